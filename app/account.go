@@ -0,0 +1,259 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"dummy-chain/common"
+	"dummy-chain/common/config"
+	"dummy-chain/keys"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip32"
+	"github.com/tyler-smith/go-bip39"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+var (
+	accountCommand = &cli.Command{
+		Name:  "account",
+		Usage: "Manage the encrypted account keys of the keystore",
+		Subcommands: []*cli.Command{
+			{
+				Action:    accountNewAction,
+				Name:      "new",
+				Usage:     "Generate a key and store it encrypted with a passphrase",
+				ArgsUsage: " ",
+			},
+			{
+				Action:    accountImportAction,
+				Name:      "import",
+				Usage:     "Import a hex private key, a key file of another keystore or, with --mnemonic, a mnemonic",
+				ArgsUsage: "file",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "mnemonic",
+						Usage: "The file holds a mnemonic, the key is derived at m/44'/60'/0'/0/index",
+					},
+					&cli.UintFlag{
+						Name:  "index",
+						Usage: "Index of the key derived from the mnemonic",
+						Value: 1,
+					},
+				},
+			},
+			{
+				Action:    accountListAction,
+				Name:      "list",
+				Usage:     "List the accounts of the keystore",
+				ArgsUsage: " ",
+			},
+			{
+				Action:    accountExportAction,
+				Name:      "export",
+				Usage:     "Print the encrypted key file of an account, or its private key with --private-key",
+				ArgsUsage: "[0xaddress]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "private-key",
+						Usage: "Print the unencrypted hex private key",
+					},
+				},
+			},
+		},
+	}
+)
+
+func openKeystore() (*config.GlobalConfig, *keys.Keystore, error) {
+	cfg, err := MakeConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, keys.Open(cfg.GetKeystorePath()), nil
+}
+
+// unlockAccount decrypts the key of the configured account
+func unlockAccount(c *cli.Context, cfg *config.GlobalConfig) (*ecdsa.PrivateKey, error) {
+	ks := keys.Open(cfg.GetKeystorePath())
+	account, err := ks.Find(cfg.Account)
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := readPassphrase(c, fmt.Sprintf("Passphrase of %s", account.Address.Hex()), false)
+	if err != nil {
+		return nil, err
+	}
+	return ks.Unlock(account, passphrase)
+}
+
+// readPassphrase reads the first line of the --password file, or prompts for it on the terminal
+func readPassphrase(c *cli.Context, prompt string, confirm bool) (string, error) {
+	if path := c.String(passwordFlag.Name); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read the password file")
+		}
+		return strings.TrimRight(strings.SplitN(string(data), "\n", 2)[0], "\r"), nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", errors.New("no terminal to prompt for the passphrase, use --password")
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		fmt.Fprint(os.Stderr, "Repeat the passphrase: ")
+		repeated, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		if string(repeated) != string(passphrase) {
+			return "", errors.New("the passphrases don't match")
+		}
+	}
+	return string(passphrase), nil
+}
+
+func accountNewAction(c *cli.Context) error {
+	if c.Args().Len() != 0 {
+		return errors.New("invalid arguments")
+	}
+	_, ks, err := openKeystore()
+	if err != nil {
+		return err
+	}
+	passphrase, err := readPassphrase(c, "Passphrase of the new account", true)
+	if err != nil {
+		return err
+	}
+	account, err := ks.New(passphrase)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created account %s in %s\n", account.Address.Hex(), account.URL.Path)
+	return nil
+}
+
+func accountImportAction(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return errors.New("invalid arguments")
+	}
+	data, err := os.ReadFile(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	_, ks, err := openKeystore()
+	if err != nil {
+		return err
+	}
+
+	if keys.IsKeyJson(data) && !c.Bool("mnemonic") {
+		passphrase, err := readPassphrase(c, "Passphrase of the key file", false)
+		if err != nil {
+			return err
+		}
+		account, err := ks.ImportJson(data, passphrase)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Imported account %s into %s\n", account.Address.Hex(), account.URL.Path)
+		return nil
+	}
+
+	var key *ecdsa.PrivateKey
+	if c.Bool("mnemonic") {
+		mnemonic := strings.Join(strings.Fields(string(data)), " ")
+		if !bip39.IsMnemonicValid(mnemonic) {
+			return errors.New("invalid mnemonic")
+		}
+		masterKey, err := bip32.NewMasterKey(bip39.NewSeed(mnemonic, ""))
+		if err != nil {
+			return err
+		}
+		if key, _, err = common.DeriveKey(masterKey, uint32(c.Uint("index"))); err != nil {
+			return err
+		}
+	} else {
+		if key, err = crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x")); err != nil {
+			return errors.Wrap(err, "invalid private key")
+		}
+	}
+
+	passphrase, err := readPassphrase(c, "Passphrase of the imported account", true)
+	if err != nil {
+		return err
+	}
+	account, err := ks.ImportKey(key, passphrase)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported account %s into %s\n", account.Address.Hex(), account.URL.Path)
+	return nil
+}
+
+func accountListAction(c *cli.Context) error {
+	if c.Args().Len() != 0 {
+		return errors.New("invalid arguments")
+	}
+	cfg, ks, err := openKeystore()
+	if err != nil {
+		return err
+	}
+	selected, _ := ks.Find(cfg.Account)
+	for i, account := range ks.Accounts() {
+		marker := " "
+		if account.Address == selected.Address {
+			marker = "*"
+		}
+		fmt.Printf("%s %d. %s %s\n", marker, i, account.Address.Hex(), account.URL.Path)
+	}
+	return nil
+}
+
+func accountExportAction(c *cli.Context) error {
+	if c.Args().Len() > 1 {
+		return errors.New("invalid arguments")
+	}
+	cfg, ks, err := openKeystore()
+	if err != nil {
+		return err
+	}
+	address := cfg.Account
+	if c.Args().Len() == 1 {
+		address = c.Args().Get(0)
+	}
+	account, err := ks.Find(address)
+	if err != nil {
+		return err
+	}
+	passphrase, err := readPassphrase(c, fmt.Sprintf("Passphrase of %s", account.Address.Hex()), false)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("private-key") {
+		key, err := ks.Unlock(account, passphrase)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "Anyone reading the private key can spend the funds of the account")
+		fmt.Println(hex.EncodeToString(crypto.FromECDSA(key)))
+		return nil
+	}
+	keyJson, err := ks.Export(account, passphrase)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(keyJson))
+	return nil
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"dummy-chain/common"
+	"dummy-chain/metadata"
+	"dummy-chain/rpc"
+	"dummy-chain/signer"
+	"fmt"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	backupCommand = &cli.Command{
+		Action:    backupAction,
+		Name:      "backup",
+		Usage:     "Write a snapshot of the database to the backups directory, through the admin RPC if the node is running",
+		ArgsUsage: "[name]",
+	}
+	restoreCommand = &cli.Command{
+		Action:    restoreAction,
+		Name:      "restore",
+		Usage:     "Load a backup into the database of a stopped node",
+		ArgsUsage: "path",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "overwrite",
+				Usage: "Replace an existing chain",
+			},
+		},
+	}
+)
+
+func backupAction(c *cli.Context) error {
+	if c.Args().Len() > 1 {
+		return errors.New("invalid arguments")
+	}
+	name := c.Args().Get(0)
+
+	var err error
+	m, err = NewManager(c, false)
+	if errors.Is(err, common.ErrDataDirUsed) {
+		// Url and the fallback urls of a client are other machines, only a validator can be asked to write its own snapshot
+		if metadata.Role != common.ValidatorRole {
+			return errors.Wrap(err, "stop the node to back it up")
+		}
+		// The node is running, let it stream the snapshot itself
+		cfg, errConfig := MakeConfig()
+		if errConfig != nil {
+			return errConfig
+		}
+		url, errUrl := cfg.RpcConfig.LocalUrl()
+		if errUrl != nil {
+			return errUrl
+		}
+		client, errClient := rpc.NewClient(url, rpc.WithAuthToken(cfg.Token))
+		if errClient != nil {
+			return errClient
+		}
+		defer client.Close()
+		info, errBackup := client.Backup(c.Context, name)
+		if errBackup != nil {
+			return errBackup
+		}
+		fmt.Printf("Backup written by the running node to %s (%d bytes)\n", info.Path, info.Size)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer func() {
+		if errStop := m.node.Stop(); errStop != nil {
+			m.logger.Error(errStop.Error())
+		}
+	}()
+
+	path, err := m.node.Backup(name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Backup written to %s\n", path)
+	return nil
+}
+
+func restoreAction(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return errors.New("invalid arguments")
+	}
+	path := c.Args().Get(0)
+
+	var err error
+	m, err = NewManager(c, false)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if errStop := m.node.Stop(); errStop != nil {
+			m.logger.Error(errStop.Error())
+		}
+	}()
+
+	minHeight, err := protectedHeight()
+	if err != nil {
+		return err
+	}
+	if err = m.node.Restore(path, c.Bool("overwrite"), minHeight); err != nil {
+		return err
+	}
+	fmt.Printf("Restored backup %s\n", path)
+	return nil
+}
+
+// protectedHeight is the last block signed with the keystore account of a validator, 0 when nothing was signed
+// A remote signer keeps its own record, which the operator has to compare with the backup
+func protectedHeight() (uint64, error) {
+	if metadata.Role != common.ValidatorRole {
+		return 0, nil
+	}
+	cfg, err := MakeConfig()
+	if err != nil {
+		return 0, err
+	}
+	if cfg.SignerConfig.RemoteUrl != "" {
+		return 0, nil
+	}
+	height, _, err := signer.ProtectedHeight(filepath.Join(cfg.GetDataPath(), slashingProtectionFile))
+	return height, err
+}
@@ -16,6 +16,15 @@ import (
 var (
 	app = cli.NewApp()
 	m   *Manager
+
+	lightFlag = &cli.BoolFlag{
+		Name:  "light",
+		Usage: "Only store block headers, accounts and transactions are fetched from the validators when needed",
+	}
+	passwordFlag = &cli.StringFlag{
+		Name:  "password",
+		Usage: "File whose first line is the keystore passphrase, instead of prompting for it",
+	}
 )
 
 func Run() {
@@ -40,9 +49,24 @@ func init() {
 	app.HideHelpCommand = true
 	app.Version = metadata.Version
 	app.Compiled = time.Now()
+	app.Flags = []cli.Flag{
+		lightFlag,
+		passwordFlag,
+	}
 	app.Commands = []*cli.Command{
 		versionCommand,
+		accountCommand,
 		sendCommand,
+		verifyChainCommand,
+		reindexCommand,
+		backupCommand,
+		restoreCommand,
+		signerCommand,
+		balanceCommand,
+		nonceCommand,
+		txCommand,
+		blockCommand,
+		historyCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -54,7 +78,8 @@ func init() {
 func beforeAction(ctx *cli.Context) error {
 	maxCpu := runtime.NumCPU()
 
-	fmt.Printf(`Node - Runtime Information
+	// Stdout is left to the output of the commands
+	fmt.Fprintf(os.Stderr, `Node - Runtime Information
 ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 Current time:	%v
 Version:	%s
@@ -69,7 +94,7 @@ Max CPU cores:	%d
 }
 func action(ctx *cli.Context) error {
 	var err error
-	m, err = NewManager(ctx)
+	m, err = NewManager(ctx, true)
 	if err != nil {
 		return err
 	}
@@ -14,7 +14,7 @@ func MakeConfig() (*config2.GlobalConfig, error) {
 	cfg := config2.NewGlobalConfig()
 
 	// 2. Load config file.
-	err := readConfigFromFile(cfg)
+	legacyMnemonic, err := readConfigFromFile(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -30,6 +30,11 @@ func MakeConfig() (*config2.GlobalConfig, error) {
 	}
 
 	// 5. Write it so a default one is created after the first run
+	// A config still holding the mnemonic is left alone, writing it would drop the mnemonic before it was imported
+	if legacyMnemonic {
+		common.GlobalLogger.Warn("The config still holds a Mnemonic: import the key with `account import --mnemonic` and remove it from config.json")
+		return cfg, nil
+	}
 	if errWrite := config2.WriteConfig(cfg); errWrite != nil {
 		return nil, errWrite
 	}
@@ -37,23 +42,30 @@ func MakeConfig() (*config2.GlobalConfig, error) {
 	return cfg, nil
 }
 
-func readConfigFromFile(cfg *config2.GlobalConfig) error {
+// readConfigFromFile also tells whether the file still holds the mnemonic the keys used to be derived from
+func readConfigFromFile(cfg *config2.GlobalConfig) (bool, error) {
 	// second read default settings
 	dataPath := cfg.GetDataPath()
 	configPath := filepath.Join(dataPath, cfg.DefaultNetworkConfigFileName())
 	if err := os.MkdirAll(dataPath, os.ModePerm); err != nil {
-		return err
+		return false, err
 	}
 
 	if jsonConf, err := ioutil.ReadFile(configPath); err == nil {
 		err = json.Unmarshal(jsonConf, &cfg)
 		if err == nil {
-			return nil
+			var legacy struct {
+				Base struct {
+					Mnemonic string
+				}
+			}
+			_ = json.Unmarshal(jsonConf, &legacy)
+			return legacy.Base.Mnemonic != "", nil
 		}
 		log.Print("GlobalConfig malformed: please check", "error", err)
-		return err
+		return false, err
 	} else {
 		log.Printf("Error when reading %s: - %s", configPath, err.Error())
 	}
-	return nil
+	return false, nil
 }
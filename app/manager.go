@@ -4,6 +4,7 @@ import (
 	"dummy-chain/common"
 	"dummy-chain/common/config"
 	"dummy-chain/node"
+	"dummy-chain/signer"
 	"os"
 	"os/signal"
 	"syscall"
@@ -19,18 +20,30 @@ type Manager struct {
 	logger *zap.Logger
 }
 
-func NewManager(ctx *cli.Context) (*Manager, error) {
+// NewManager creates the node, unlock connects to the signer of the node or asks for the passphrase of its account
+// Commands that only work on the database leave the key locked
+func NewManager(ctx *cli.Context, unlock bool) (*Manager, error) {
 	newConfig, err := MakeConfig()
 	if err != nil {
 		return nil, err
 	}
+	if ctx.Bool(lightFlag.Name) {
+		newConfig.Light = true
+	}
+
+	var nodeSigner signer.Signer
+	if unlock {
+		if nodeSigner, err = newSigner(ctx, newConfig); err != nil {
+			return nil, err
+		}
+	}
 
 	logger, err := common.CreateLogger()
 	if err != nil {
 		return nil, err
 	}
 
-	newNode, err := node.NewNode(newConfig, logger)
+	newNode, err := node.NewNode(newConfig, nodeSigner, logger)
 
 	if err != nil {
 		logger.Info("failed to create the node", zap.String("reason", err.Error()))
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	reindexCommand = &cli.Command{
+		Action:    reindexAction,
+		Name:      "reindex",
+		Usage:     "Rebuild accounts and indexes from the stored blocks",
+		ArgsUsage: " ",
+	}
+)
+
+func reindexAction(c *cli.Context) error {
+	var err error
+	m, err = NewManager(c, false)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if errStop := m.node.Stop(); errStop != nil {
+			m.logger.Error(errStop.Error())
+		}
+	}()
+
+	if err = m.node.Reindex(func(height, target uint64) {
+		fmt.Printf("Reindexed block %d/%d (%.2f%%)\n", height, target, float64(height+1)*100/float64(target+1))
+	}); err != nil {
+		return err
+	}
+
+	fmt.Println("Reindex finished")
+	return nil
+}
@@ -25,7 +25,7 @@ func sendAction(c *cli.Context) error {
 	value := c.Args().Get(1)
 
 	var err error
-	m, err = NewManager(c)
+	m, err = NewManager(c, true)
 	if err != nil {
 		return err
 	}
@@ -33,6 +33,8 @@ func sendAction(c *cli.Context) error {
 		m.logger.Fatal("failed to start node", zap.String("reason", err.Error()))
 		os.Exit(1)
 	}
+	// Stopping flushes the transaction to the peers it was relayed to
+	defer m.node.Stop()
 
 	return m.node.SendTransaction(to, value)
 }
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"dummy-chain/common"
+	"dummy-chain/common/config"
+	"dummy-chain/signer"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// File of the data dir holding the last block signed with the keystore account
+const slashingProtectionFile = "slashing-protection.json"
+
+var (
+	signerCommand = &cli.Command{
+		Action:    signerAction,
+		Name:      "signer",
+		Usage:     "Sign blocks and transactions with the keystore account for a validator, over HTTP or a Unix socket",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "listen",
+				Usage: "host:port or unix:///path/to/socket, Signer.ListenAddress of the config by default",
+			},
+		},
+	}
+)
+
+// newSigner connects to the remote signer of the config, or unlocks the keystore account
+func newSigner(c *cli.Context, cfg *config.GlobalConfig) (signer.Signer, error) {
+	if cfg.SignerConfig.RemoteUrl != "" {
+		return signer.NewRemote(c.Context, cfg.SignerConfig.RemoteUrl, cfg.SignerConfig.AuthToken)
+	}
+	return newLocalSigner(c, cfg)
+}
+
+func newLocalSigner(c *cli.Context, cfg *config.GlobalConfig) (*signer.Local, error) {
+	key, err := unlockAccount(c, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return signer.NewLocal(key, filepath.Join(cfg.GetDataPath(), slashingProtectionFile))
+}
+
+func signerAction(c *cli.Context) error {
+	if c.Args().Len() != 0 {
+		return errors.New("invalid arguments")
+	}
+	cfg, err := MakeConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.SignerConfig.RemoteUrl != "" {
+		return errors.New("the signer signs with the keystore account, Signer.RemoteUrl must be empty")
+	}
+	address := cfg.SignerConfig.ListenAddress
+	if c.String("listen") != "" {
+		address = c.String("listen")
+	}
+
+	local, err := newLocalSigner(c, cfg)
+	if err != nil {
+		return err
+	}
+	logger, err := common.CreateLogger()
+	if err != nil {
+		return err
+	}
+	server := signer.NewServer(local, cfg.SignerConfig.AuthToken, logger)
+
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), signer.ShutdownTimeout)
+		defer cancel()
+		_ = server.Stop(ctx)
+	}()
+	return server.Serve(address)
+}
@@ -0,0 +1,329 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"dummy-chain/common"
+	"dummy-chain/common/types"
+	"dummy-chain/keys"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip32"
+	"github.com/tyler-smith/go-bip39"
+	"github.com/urfave/cli/v2"
+)
+
+// The offline workflow: tx build on a machine reaching the validator, tx sign on the one holding the key,
+// tx broadcast back on the first one. Transactions are passed around as files or through stdin with -
+var (
+	txBuildCommand = &cli.Command{
+		Action:    txBuildAction,
+		Name:      "build",
+		Usage:     "Print an unsigned transfer as JSON, offline when --nonce is given",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "Sender, the configured account by default",
+			},
+			&cli.StringFlag{
+				Name:     "to",
+				Usage:    "Recipient",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "value",
+				Usage:    "Amount in coins, decimals allowed",
+				Required: true,
+			},
+			&cli.Uint64Flag{
+				Name:  "nonce",
+				Usage: "Nonce of the transaction, asked to the validator with a balance check when not set",
+			},
+		},
+	}
+	txSignCommand = &cli.Command{
+		Action:    txSignAction,
+		Name:      "sign",
+		Usage:     "Sign an unsigned transaction with the keystore account of its sender, or a key derived from a mnemonic, without network access",
+		ArgsUsage: "file|-",
+		Flags: []cli.Flag{
+			jsonFlag,
+			&cli.StringFlag{
+				Name:  "mnemonic",
+				Usage: "File holding the mnemonic to derive the key from instead of the keystore",
+			},
+			&cli.UintFlag{
+				Name:  "index",
+				Usage: "Index of the key derived from the mnemonic",
+				Value: 1,
+			},
+		},
+	}
+	txBroadcastCommand = &cli.Command{
+		Action:    txBroadcastAction,
+		Name:      "broadcast",
+		Usage:     "Submit a signed transaction, base64 gob or 0x raw Ethereum, to the validator",
+		ArgsUsage: "file|-|transaction",
+		Flags:     []cli.Flag{jsonFlag},
+	}
+	txDecodeCommand = &cli.Command{
+		Action:    txDecodeAction,
+		Name:      "decode",
+		Usage:     "Print a JSON, base64 gob or 0x raw Ethereum transaction and check its signature",
+		ArgsUsage: "file|-|transaction",
+		Flags:     []cli.Flag{jsonFlag},
+	}
+)
+
+// DecodedTransaction is the output of tx decode
+type DecodedTransaction struct {
+	Transaction types.TransactionInfo
+	Signed      bool
+	// Whether the hash matches the content and the signature the sender
+	Valid bool
+	Error string `json:",omitempty"`
+}
+
+// readTransactionArg returns the transaction given as argument: - reads stdin, an existing path reads the file
+// and anything else is the transaction itself
+func readTransactionArg(c *cli.Context) (string, error) {
+	if c.Args().Len() != 1 {
+		return "", errors.New("invalid arguments")
+	}
+	arg := c.Args().Get(0)
+	if arg == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		return strings.TrimSpace(string(data)), err
+	}
+	if _, err := os.Stat(arg); err == nil {
+		data, err := os.ReadFile(arg)
+		return strings.TrimSpace(string(data)), err
+	}
+	return strings.TrimSpace(arg), nil
+}
+
+// decodeTransaction accepts the JSON of tx build, the base64 gob of chain.SendTransaction and 0x raw Ethereum transactions
+// The transaction isn't verified
+func decodeTransaction(input string) (*types.Transaction, error) {
+	switch {
+	case strings.HasPrefix(input, "{"):
+		var info types.TransactionInfo
+		if err := json.Unmarshal([]byte(input), &info); err != nil {
+			return nil, errors.Wrap(err, "invalid transaction JSON")
+		}
+		if info.Value == nil {
+			return nil, errors.New("invalid transaction JSON: missing value")
+		}
+		return info.ToTransaction()
+	case strings.HasPrefix(input, "0x"):
+		raw, err := hexutil.Decode(input)
+		if err != nil {
+			return nil, err
+		}
+		return types.DecodeEthereumTransaction(raw)
+	}
+	tx, err := types.DecodeBase64Transaction(input)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid base64 transaction")
+	}
+	return tx, nil
+}
+
+func printTransactionJson(tx *types.Transaction) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(tx.ToInfo())
+}
+
+func txBuildAction(c *cli.Context) error {
+	if c.Args().Len() != 0 {
+		return errors.New("invalid arguments")
+	}
+	to := c.String("to")
+	if !ecommon.IsHexAddress(to) {
+		return errors.Errorf("invalid address %s", to)
+	}
+	value, err := common.ParseToBigInt(c.String("value"))
+	if err != nil {
+		return err
+	}
+	if value.Sign() < 0 {
+		return errors.New("negative value")
+	}
+
+	cfg, err := MakeConfig()
+	if err != nil {
+		return err
+	}
+	from := ecommon.HexToAddress(c.String("from"))
+	if c.String("from") == "" {
+		if from, err = walletAddress(c, cfg); err != nil {
+			return err
+		}
+	} else if !ecommon.IsHexAddress(c.String("from")) {
+		return errors.Errorf("invalid address %s", c.String("from"))
+	}
+
+	if c.IsSet("nonce") {
+		return printTransactionJson(types.NewTransaction(from, c.Uint64("nonce"), ecommon.HexToAddress(to), value))
+	}
+	client, err := newRpcClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	tx, err := client.BuildTransaction(c.Context, from, ecommon.HexToAddress(to), value)
+	if err != nil {
+		return err
+	}
+	return printTransactionJson(tx)
+}
+
+func txSignAction(c *cli.Context) error {
+	input, err := readTransactionArg(c)
+	if err != nil {
+		return err
+	}
+	tx, err := decodeTransaction(input)
+	if err != nil {
+		return err
+	}
+	if len(tx.Raw) > 0 {
+		return errors.New("an Ethereum transaction is signed by its own wallet")
+	}
+	// The hash is what gets signed, it must describe the transfer shown below
+	if tx.Hash != tx.GetHash() {
+		return errors.New("the transaction hash doesn't match its content")
+	}
+
+	fmt.Fprintf(os.Stderr, "Signing the transfer of %s from %s to %s with nonce %d\n",
+		common.FormatBigInt(tx.Value), tx.From.Hex(), tx.To.Hex(), tx.Nonce)
+	key, err := signingKey(c, tx.From)
+	if err != nil {
+		return err
+	}
+	if err = tx.Sign(key); err != nil {
+		return err
+	}
+
+	if c.Bool(jsonFlag.Name) {
+		return printTransactionJson(tx)
+	}
+	encoded, err := tx.EncodeBase64()
+	if err != nil {
+		return err
+	}
+	fmt.Println(encoded)
+	return nil
+}
+
+// signingKey returns the key of from, derived from the --mnemonic file or unlocked from the keystore
+func signingKey(c *cli.Context, from ecommon.Address) (*ecdsa.PrivateKey, error) {
+	if path := c.String("mnemonic"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		mnemonic := strings.Join(strings.Fields(string(data)), " ")
+		if !bip39.IsMnemonicValid(mnemonic) {
+			return nil, errors.New("invalid mnemonic")
+		}
+		masterKey, err := bip32.NewMasterKey(bip39.NewSeed(mnemonic, ""))
+		if err != nil {
+			return nil, err
+		}
+		key, address, err := common.DeriveKey(masterKey, uint32(c.Uint("index")))
+		if err != nil {
+			return nil, err
+		}
+		if *address != from {
+			return nil, errors.Errorf("the key at index %d is %s, not the sender %s", c.Uint("index"), address.Hex(), from.Hex())
+		}
+		return key, nil
+	}
+
+	cfg, err := MakeConfig()
+	if err != nil {
+		return nil, err
+	}
+	ks := keys.Open(cfg.GetKeystorePath())
+	account, err := ks.Find(from.Hex())
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := readPassphrase(c, fmt.Sprintf("Passphrase of %s", from.Hex()), false)
+	if err != nil {
+		return nil, err
+	}
+	return ks.Unlock(account, passphrase)
+}
+
+func txBroadcastAction(c *cli.Context) error {
+	input, err := readTransactionArg(c)
+	if err != nil {
+		return err
+	}
+	tx, err := decodeTransaction(input)
+	if err != nil {
+		return err
+	}
+	// The validator would refuse it anyway, this tells why
+	if err = tx.Validate(); err != nil {
+		return err
+	}
+
+	_, client, err := walletClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err = client.SendTransaction(c.Context, tx); err != nil {
+		return err
+	}
+	result := struct {
+		Hash string
+	}{tx.Hash.Hex()}
+	return printResult(c, result, func() {
+		fmt.Println(tx.Hash.Hex())
+	})
+}
+
+func txDecodeAction(c *cli.Context) error {
+	input, err := readTransactionArg(c)
+	if err != nil {
+		return err
+	}
+	tx, err := decodeTransaction(input)
+	if err != nil {
+		return err
+	}
+
+	decoded := DecodedTransaction{
+		Transaction: tx.ToInfo(),
+		Signed:      len(tx.Signature) > 0 || len(tx.Raw) > 0,
+	}
+	if err = tx.Validate(); err != nil {
+		decoded.Error = err.Error()
+	} else {
+		decoded.Valid = true
+	}
+
+	return printResult(c, decoded, func() {
+		fmt.Println(tx.String())
+		switch {
+		case decoded.Valid:
+			fmt.Println("Signature is valid")
+		case !decoded.Signed && tx.Hash == tx.GetHash():
+			fmt.Println("Not signed")
+		default:
+			fmt.Printf("Invalid: %s\n", decoded.Error)
+		}
+	})
+}
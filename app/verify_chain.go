@@ -0,0 +1,53 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	verifyChainCommand = &cli.Command{
+		Action:    verifyChainAction,
+		Name:      "verify-chain",
+		Usage:     "Check the integrity of the local database",
+		ArgsUsage: " ",
+	}
+)
+
+func verifyChainAction(c *cli.Context) error {
+	var err error
+	m, err = NewManager(c, false)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if errStop := m.node.Stop(); errStop != nil {
+			m.logger.Error(errStop.Error())
+		}
+	}()
+
+	report, err := m.node.VerifyChain()
+	if err != nil {
+		return err
+	}
+
+	for _, issue := range report.Issues {
+		fmt.Println(issue)
+	}
+	fmt.Printf(`Chain verification
+━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+Height:         %d
+Blocks:         %d
+Transactions:   %d
+Accounts:       %d
+Issues:         %d
+━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+`, report.Height, report.Blocks, report.Transactions, report.Accounts, len(report.Issues))
+
+	if !report.Ok() {
+		return errors.New("chain verification failed")
+	}
+	return nil
+}
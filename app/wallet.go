@@ -0,0 +1,325 @@
+package app
+
+import (
+	"dummy-chain/common"
+	"dummy-chain/common/config"
+	"dummy-chain/common/types"
+	"dummy-chain/keys"
+	"dummy-chain/rpc"
+	"dummy-chain/signer"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// The wallet commands only talk to the validator, they neither open the database nor sync
+var (
+	jsonFlag = &cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the result as JSON",
+	}
+
+	balanceCommand = &cli.Command{
+		Action:    balanceAction,
+		Name:      "balance",
+		Usage:     "Print the balance of an account, the configured one by default",
+		ArgsUsage: "[0xaddress]",
+		Flags:     []cli.Flag{jsonFlag},
+	}
+	nonceCommand = &cli.Command{
+		Action:    nonceAction,
+		Name:      "nonce",
+		Usage:     "Print the nonce the next transaction of an account must use, the configured one by default",
+		ArgsUsage: "[0xaddress]",
+		Flags:     []cli.Flag{jsonFlag},
+	}
+	txCommand = &cli.Command{
+		Action:    txAction,
+		Name:      "tx",
+		Usage:     "Print a transaction and its confirmations, or build, sign, broadcast and decode transactions",
+		ArgsUsage: "0xhash",
+		Flags:     []cli.Flag{jsonFlag},
+		Subcommands: []*cli.Command{
+			txBuildCommand,
+			txSignCommand,
+			txBroadcastCommand,
+			txDecodeCommand,
+		},
+	}
+	blockCommand = &cli.Command{
+		Action:    blockAction,
+		Name:      "block",
+		Usage:     "Print a block by height or by hash",
+		ArgsUsage: "height|0xhash",
+		Flags:     []cli.Flag{jsonFlag},
+	}
+	historyCommand = &cli.Command{
+		Action:    historyAction,
+		Name:      "history",
+		Usage:     "Print the transactions sent or received by an account, newest first",
+		ArgsUsage: "[0xaddress]",
+		Flags: []cli.Flag{
+			jsonFlag,
+			&cli.IntFlag{
+				Name:  "offset",
+				Usage: "Number of newer transactions to skip",
+			},
+			&cli.IntFlag{
+				Name:  "limit",
+				Usage: "Most transactions printed",
+				Value: 20,
+			},
+		},
+	}
+)
+
+// TransactionStatus is the output of the tx command, Transaction is nil when the validator doesn't know the hash
+type TransactionStatus struct {
+	Hash          string
+	Status        string
+	Confirmations uint64
+	Transaction   *types.TransactionInfo `json:",omitempty"`
+}
+
+const (
+	committedStatus = "committed"
+	// The transaction may still be in the mempool, the chain API only serves committed transactions
+	unknownStatus = "unknown"
+)
+
+// newRpcClient connects to the validators of the config
+func newRpcClient(cfg *config.GlobalConfig) (*rpc.Client, error) {
+	return rpc.NewClient(cfg.Url,
+		rpc.WithFallbackUrls(cfg.FallbackUrls...),
+		rpc.WithAuthToken(cfg.Token),
+	)
+}
+
+// walletClient loads the config and connects to the validator
+func walletClient() (*config.GlobalConfig, *rpc.Client, error) {
+	cfg, err := MakeConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := newRpcClient(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, client, nil
+}
+
+// walletAddress is the address given as argument, or the account the node signs with
+// The keystore only gives the address without the passphrase
+func walletAddress(c *cli.Context, cfg *config.GlobalConfig) (ecommon.Address, error) {
+	if c.Args().Len() > 1 {
+		return ecommon.Address{}, errors.New("invalid arguments")
+	}
+	if c.Args().Len() == 1 {
+		address := c.Args().Get(0)
+		if !ecommon.IsHexAddress(address) {
+			return ecommon.Address{}, errors.Errorf("invalid address %s", address)
+		}
+		return ecommon.HexToAddress(address), nil
+	}
+
+	if cfg.SignerConfig.RemoteUrl != "" {
+		remote, err := signer.NewRemote(c.Context, cfg.SignerConfig.RemoteUrl, cfg.SignerConfig.AuthToken)
+		if err != nil {
+			return ecommon.Address{}, err
+		}
+		return remote.Address(), nil
+	}
+	account, err := keys.Open(cfg.GetKeystorePath()).Find(cfg.Account)
+	if err != nil {
+		return ecommon.Address{}, err
+	}
+	return account.Address, nil
+}
+
+// printResult prints value as JSON with --json, and calls human otherwise
+func printResult(c *cli.Context, value interface{}, human func()) error {
+	if !c.Bool(jsonFlag.Name) {
+		human()
+		return nil
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(value)
+}
+
+func balanceAction(c *cli.Context) error {
+	cfg, client, err := walletClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	address, err := walletAddress(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	account, err := client.GetAccountInfo(c.Context, address)
+	if err != nil {
+		return err
+	}
+	return printResult(c, account, func() {
+		fmt.Printf("%s %s\n", address.Hex(), account.Balance)
+	})
+}
+
+func nonceAction(c *cli.Context) error {
+	cfg, client, err := walletClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	address, err := walletAddress(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	account, err := client.GetAccountInfo(c.Context, address)
+	if err != nil {
+		return err
+	}
+	result := struct {
+		Address string
+		Nonce   uint64
+	}{address.Hex(), account.Nonce}
+	return printResult(c, result, func() {
+		fmt.Println(account.Nonce)
+	})
+}
+
+func txAction(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return errors.New("invalid arguments")
+	}
+	hash, err := parseHashArg(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	_, client, err := walletClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	status := TransactionStatus{Hash: hash.Hex(), Status: unknownStatus}
+	tx, err := client.GetTransactionByHash(c.Context, hash)
+	if err == nil {
+		height, errHeight := client.GetCurrentBlockHeight(c.Context)
+		if errHeight != nil {
+			return errHeight
+		}
+		status.Status, status.Transaction = committedStatus, tx
+		if height >= tx.BlockHeight {
+			status.Confirmations = height - tx.BlockHeight + 1
+		}
+	} else if !errors.Is(err, common.ErrNotFound) {
+		return err
+	}
+
+	return printResult(c, status, func() {
+		if status.Transaction == nil {
+			fmt.Printf("Transaction %s is not committed, it is pending or unknown\n", status.Hash)
+			return
+		}
+		fmt.Printf(`Hash:           %s
+Status:         %s
+Block:          %d
+Confirmations:  %d
+From:           %s
+To:             %s
+Nonce:          %d
+Value:          %s
+`, tx.Hash, status.Status, tx.BlockHeight, status.Confirmations, tx.From, tx.To, tx.Nonce, formatValue(tx.Value))
+	})
+}
+
+func blockAction(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return errors.New("invalid arguments")
+	}
+	_, client, err := walletClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var block *types.BlockInfo
+	id := c.Args().Get(0)
+	if height, errParse := strconv.ParseUint(id, 10, 64); errParse == nil {
+		block, err = client.GetBlockByHeight(c.Context, height)
+	} else {
+		hash, errHash := parseHashArg(id)
+		if errHash != nil {
+			return errHash
+		}
+		block, err = client.GetBlockByHash(c.Context, hash)
+	}
+	if err != nil {
+		return err
+	}
+
+	return printResult(c, block, func() {
+		fmt.Printf(`Height:        %d
+Hash:          %s
+Previous:      %s
+Timestamp:     %d
+Validator:     %s
+Transactions:  %d
+`, block.Height, block.Hash, block.PrevHash, block.Timestamp, block.Validator, len(block.Transactions))
+		for _, tx := range block.Transactions {
+			fmt.Printf("  %s %s -> %s %s\n", tx.Hash, tx.From, tx.To, formatValue(tx.Value))
+		}
+	})
+}
+
+func historyAction(c *cli.Context) error {
+	cfg, client, err := walletClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	address, err := walletAddress(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	list, err := client.GetAccountTransactions(c.Context, address, c.Int("offset"), c.Int("limit"))
+	if err != nil {
+		return err
+	}
+	return printResult(c, list, func() {
+		for _, tx := range list.Transactions {
+			direction := "in "
+			if ecommon.HexToAddress(tx.From) == address {
+				direction = "out"
+			}
+			fmt.Printf("%d %s %s %s -> %s %s\n", tx.BlockHeight, tx.Hash, direction, tx.From, tx.To, formatValue(tx.Value))
+		}
+	})
+}
+
+func parseHashArg(value string) (ecommon.Hash, error) {
+	bytes, err := hexutil.Decode(value)
+	if err != nil || len(bytes) != ecommon.HashLength {
+		return ecommon.Hash{}, errors.Errorf("invalid hash %s", value)
+	}
+	return ecommon.BytesToHash(bytes), nil
+}
+
+func formatValue(value *big.Int) string {
+	if value == nil {
+		return "0"
+	}
+	return common.FormatBigInt(value)
+}
@@ -0,0 +1,32 @@
+package config
+
+import "fmt"
+
+// AuthConfig restricts what callers of the validator servers may do
+// Permissions are "read" (queries, subscriptions, events), "send" (transaction submission) and "admin"
+// When Enabled is false every caller has every permission
+type AuthConfig struct {
+	Enabled bool
+	// Permissions of callers without credentials
+	Public []string
+	// Static bearer tokens and the permissions each one grants
+	Tokens map[string][]string
+	// File holding a hex encoded secret of at least 32 bytes, HS256 JWTs signed with it and issued within a minute grant JwtPermissions
+	JwtSecretFile  string
+	JwtPermissions []string
+	// Client certificates signed by this CA grant ClientCertPermissions, the Rpc server must use TLS
+	ClientCaFile          string
+	ClientCertPermissions []string
+}
+
+func (c *AuthConfig) AsMap() map[string]interface{} {
+	return map[string]interface{}{
+		"Enabled":               c.Enabled,
+		"Public":                c.Public,
+		"Tokens":                fmt.Sprintf("%d REDACTED", len(c.Tokens)),
+		"JwtSecretFile":         c.JwtSecretFile,
+		"JwtPermissions":        c.JwtPermissions,
+		"ClientCaFile":          c.ClientCaFile,
+		"ClientCertPermissions": c.ClientCertPermissions,
+	}
+}
@@ -0,0 +1,18 @@
+package config
+
+// CacheConfig holds the number of entries kept in the storage read caches, 0 disables a cache
+type CacheConfig struct {
+	Blocks       int
+	Transactions int
+	Accounts     int
+	Heights      int
+}
+
+func (c *CacheConfig) AsMap() map[string]interface{} {
+	return map[string]interface{}{
+		"Blocks":       c.Blocks,
+		"Transactions": c.Transactions,
+		"Accounts":     c.Accounts,
+		"Heights":      c.Heights,
+	}
+}
@@ -6,21 +6,33 @@ import (
 )
 
 type BaseConfig struct {
-	DataPath     string
-	Mnemonic     string
-	AccountIndex uint32
-	Url          string
+	DataPath string
+	// Keystore account the node signs with, it can be left empty when the keystore holds a single account
+	Account string
+	Url     string
+	// Validators tried in order when Url can't be reached
+	FallbackUrls []string
+	// Bearer token sent to the validator at Url when it requires authentication
+	Token string
+	// Clients only keep the block headers and ask the validators for accounts and transactions
+	Light bool
 }
 
 func (c *BaseConfig) AsMap() map[string]interface{} {
 	return map[string]interface{}{
 		"DataPath":     c.DataPath,
-		"Mnemonic":     "REDACTED",
-		"AccountIndex": c.AccountIndex,
+		"Account":      c.Account,
 		"Url":          c.Url,
+		"FallbackUrls": c.FallbackUrls,
+		"Token":        "REDACTED",
+		"Light":        c.Light,
 	}
 }
 
+func (c *BaseConfig) GetKeystorePath() string {
+	return filepath.Join(c.DataPath, common.DefaultKeystoreDir)
+}
+
 func (c *BaseConfig) MakePathsAbsolute() error {
 	if c.DataPath == "" {
 		c.DataPath = common.DefaultDataDir()
@@ -4,19 +4,69 @@ import (
 	"dummy-chain/common"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 )
 
 type GlobalConfig struct {
-	BaseConfig `json:"Base"`
+	BaseConfig  `json:"Base"`
+	CacheConfig `json:"Cache"`
+	RpcConfig   `json:"Rpc"`
+	GrpcConfig  `json:"Grpc"`
+	AuthConfig  `json:"Auth"`
+
+	RateLimitConfig `json:"RateLimit"`
+	P2pConfig       `json:"P2p"`
+	SignerConfig    `json:"Signer"`
 }
 
 func NewGlobalConfig() *GlobalConfig {
 	return &GlobalConfig{
 		BaseConfig: BaseConfig{
 			DataPath:     common.DefaultDataDir(),
-			Mnemonic:     "",
-			AccountIndex: 1,
+			Account:      "",
 			Url:          "http://127.0.0.1:12345",
+			FallbackUrls: []string{},
+		},
+		CacheConfig: CacheConfig{
+			Blocks:       4096,
+			Transactions: 16384,
+			Accounts:     8192,
+			Heights:      4096,
+		},
+		RpcConfig: RpcConfig{
+			Address:      "127.0.0.1:12345",
+			CorsOrigins:  []string{},
+			ReadTimeout:  30,
+			WriteTimeout: 30,
+			MaxBodySize:  5 * 1024 * 1024,
+		},
+		GrpcConfig: GrpcConfig{
+			Address: "127.0.0.1:12346",
+		},
+		AuthConfig: AuthConfig{
+			Enabled:               false,
+			Public:                []string{"read"},
+			Tokens:                map[string][]string{},
+			JwtPermissions:        []string{"read", "send", "admin"},
+			ClientCertPermissions: []string{"read", "send", "admin"},
+		},
+		RateLimitConfig: RateLimitConfig{
+			Enabled:        true,
+			CheapRate:      100,
+			CheapBurst:     200,
+			ExpensiveRate:  10,
+			ExpensiveBurst: 50,
+			MaxClients:     10000,
+		},
+		P2pConfig: P2pConfig{
+			ListenAddress: "",
+			Peers:         []string{},
+			Bootnodes:     []string{},
+			MaxPeers:      25,
+		},
+		SignerConfig: SignerConfig{
+			RemoteUrl:     "",
+			ListenAddress: "unix://" + filepath.Join(common.DefaultDataDir(), "signer.sock"),
 		},
 	}
 }
@@ -28,6 +78,13 @@ func (c *GlobalConfig) AsMap() map[string]interface{} {
 	_ = json.Unmarshal(bytes, &result)
 
 	result["Base"] = c.BaseConfig.AsMap()
+	result["Cache"] = c.CacheConfig.AsMap()
+	result["Rpc"] = c.RpcConfig.AsMap()
+	result["Grpc"] = c.GrpcConfig.AsMap()
+	result["Auth"] = c.AuthConfig.AsMap()
+	result["RateLimit"] = c.RateLimitConfig.AsMap()
+	result["P2p"] = c.P2pConfig.AsMap()
+	result["Signer"] = c.SignerConfig.AsMap()
 	return result
 }
 
@@ -35,10 +92,6 @@ func (c *GlobalConfig) GetDataPath() string {
 	return c.DataPath
 }
 
-func (c *GlobalConfig) GetMnemonic() string {
-	return c.Mnemonic
-}
-
 func (c *GlobalConfig) DefaultNetworkConfigFileName() string {
 	return fmt.Sprintf("config.json")
 }
@@ -0,0 +1,21 @@
+package config
+
+// GrpcConfig controls the gRPC server of the validator, an empty Address disables it
+type GrpcConfig struct {
+	Address string
+	// TLS is enabled when both files are set, client certificates signed by Auth.ClientCaFile are then accepted
+	TlsCertFile string
+	TlsKeyFile  string
+}
+
+func (c *GrpcConfig) AsMap() map[string]interface{} {
+	return map[string]interface{}{
+		"Address":     c.Address,
+		"TlsCertFile": c.TlsCertFile,
+		"TlsKeyFile":  c.TlsKeyFile,
+	}
+}
+
+func (c *GrpcConfig) TlsEnabled() bool {
+	return c.TlsCertFile != "" && c.TlsKeyFile != ""
+}
@@ -0,0 +1,30 @@
+package config
+
+// P2pConfig controls the gossip network between nodes, it is disabled when there is no listen address, peer or bootnode
+type P2pConfig struct {
+	// TCP address to accept peers on, empty to only dial out
+	ListenAddress string
+	// host:port of the static nodes to stay connected to, even above MaxPeers
+	Peers []string
+	// host:port of the nodes dialed first to learn about other peers
+	Bootnodes []string
+	// Most peers connected at once, static peers excluded
+	MaxPeers int
+	// Address of the validator whose blocks are accepted from peers
+	// When empty the validator of the first synced block is trusted
+	ValidatorAddress string
+}
+
+func (c *P2pConfig) AsMap() map[string]interface{} {
+	return map[string]interface{}{
+		"ListenAddress":    c.ListenAddress,
+		"Peers":            c.Peers,
+		"Bootnodes":        c.Bootnodes,
+		"MaxPeers":         c.MaxPeers,
+		"ValidatorAddress": c.ValidatorAddress,
+	}
+}
+
+func (c *P2pConfig) Enabled() bool {
+	return c.ListenAddress != "" || len(c.Peers) > 0 || len(c.Bootnodes) > 0
+}
@@ -0,0 +1,27 @@
+package config
+
+// RateLimitConfig limits how fast each client may call the validator servers
+// A client is identified by its API key when it authenticates with a token, by its IP address otherwise
+// Each client has a token bucket for cheap reads and another one for expensive calls like GetBlocksInterval and SendTransaction
+type RateLimitConfig struct {
+	Enabled bool
+	// Sustained calls per second and burst size of the cheap bucket
+	CheapRate  float64
+	CheapBurst int
+	// Sustained calls per second and burst size of the expensive bucket
+	ExpensiveRate  float64
+	ExpensiveBurst int
+	// Largest number of clients tracked at once, new clients are limited while the table is full of active ones
+	MaxClients int
+}
+
+func (c *RateLimitConfig) AsMap() map[string]interface{} {
+	return map[string]interface{}{
+		"Enabled":        c.Enabled,
+		"CheapRate":      c.CheapRate,
+		"CheapBurst":     c.CheapBurst,
+		"ExpensiveRate":  c.ExpensiveRate,
+		"ExpensiveBurst": c.ExpensiveBurst,
+		"MaxClients":     c.MaxClients,
+	}
+}
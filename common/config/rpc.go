@@ -0,0 +1,52 @@
+package config
+
+import "net"
+
+// RpcConfig controls the HTTP server of the validator serving JSON-RPC, REST, GraphQL, websockets and events
+type RpcConfig struct {
+	// Address to listen on, use 0.0.0.0:12345 to accept connections from other machines
+	Address string
+	// TLS is enabled when both files are set
+	TlsCertFile string
+	TlsKeyFile  string
+	// Origins allowed to call the server from a browser, "*" allows any origin
+	CorsOrigins []string
+	// Timeouts in seconds for reading a whole request and writing a reply, 0 means no timeout
+	// Event streams and websockets are not affected by the write timeout
+	ReadTimeout  int
+	WriteTimeout int
+	// Largest request body accepted, in bytes
+	MaxBodySize int64
+}
+
+func (c *RpcConfig) AsMap() map[string]interface{} {
+	return map[string]interface{}{
+		"Address":      c.Address,
+		"TlsCertFile":  c.TlsCertFile,
+		"TlsKeyFile":   c.TlsKeyFile,
+		"CorsOrigins":  c.CorsOrigins,
+		"ReadTimeout":  c.ReadTimeout,
+		"WriteTimeout": c.WriteTimeout,
+		"MaxBodySize":  c.MaxBodySize,
+	}
+}
+
+func (c *RpcConfig) TlsEnabled() bool {
+	return c.TlsCertFile != "" && c.TlsKeyFile != ""
+}
+
+// LocalUrl is the url processes of the same machine reach the server at, an unspecified host becomes localhost
+func (c *RpcConfig) LocalUrl() (string, error) {
+	host, port, err := net.SplitHostPort(c.Address)
+	if err != nil {
+		return "", err
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+	scheme := "http"
+	if c.TlsEnabled() {
+		scheme = "https"
+	}
+	return scheme + "://" + net.JoinHostPort(host, port), nil
+}
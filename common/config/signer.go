@@ -0,0 +1,19 @@
+package config
+
+// SignerConfig moves the key of the node to a separate signer process, an empty RemoteUrl signs with the keystore account
+type SignerConfig struct {
+	// Remote signer used by the node, http(s)://host:port or unix:///path/to/socket
+	RemoteUrl string
+	// Bearer token sent to the remote signer, and required by the signer command when set
+	AuthToken string
+	// Address the signer command listens on, host:port or unix:///path/to/socket
+	ListenAddress string
+}
+
+func (c *SignerConfig) AsMap() map[string]interface{} {
+	return map[string]interface{}{
+		"RemoteUrl":     c.RemoteUrl,
+		"AuthToken":     "REDACTED",
+		"ListenAddress": c.ListenAddress,
+	}
+}
@@ -15,8 +15,9 @@ func WriteConfig(cfg *GlobalConfig) error {
 	if err != nil {
 		return err
 	}
-	if err = os.WriteFile(configPath, configBytes, 0644); err != nil {
+	// The config holds the authentication secrets, WriteFile keeps the mode of an existing file
+	if err = os.WriteFile(configPath, configBytes, 0600); err != nil {
 		return err
 	}
-	return nil
+	return os.Chmod(configPath, 0600)
 }
@@ -13,8 +13,18 @@ const (
 	CoinDecimals = 18
 	CoinSymbol   = "GO"
 
+	// Largest number of blocks a single GetBlocksInterval call may ask for
+	MaxBlocksInterval = 100
+	// Largest number of headers a single GetHeadersInterval call may ask for
+	MaxHeadersInterval = 1000
+	// Largest page of an account transaction history
+	MaxAccountTransactions = 100
+
 	DummyAddressStr   = "0x00000000000000000000000000000000DeaDBeef"
 	DefaultStorageDir = "storage"
+	DefaultBackupDir  = "backups"
+	// Directory of the data dir holding the encrypted account keys
+	DefaultKeystoreDir = "keystore"
 )
 
 var (
@@ -9,4 +9,9 @@ var (
 	ErrNotFound             = errors.New("not found")
 	ErrAddressNotValid      = errors.New("Address is not valid")
 	ErrNotEnoughBalanceUser = errors.New("Not enough balance")
+	ErrInvalidSignature     = errors.New("Invalid signature")
+	ErrDatabaseNotEmpty     = errors.New("database is not empty")
+	ErrIntervalTooLarge     = errors.New("block interval is too large")
+	ErrInvalidTransaction   = errors.New("invalid transaction")
+	ErrBackupBehindSigner   = errors.New("backup is below the last block signed by the validator")
 )
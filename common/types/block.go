@@ -118,3 +118,76 @@ type BlockInfoList struct {
 	Count  uint64
 	Blocks []BlockInfo
 }
+
+// HeaderInfo is the RPC representation of a block without its transactions, only their hashes
+type HeaderInfo struct {
+	ChainId      uint64
+	Hash         string
+	Height       uint64
+	Timestamp    int64
+	PrevHash     string
+	Validator    string
+	Signature    string
+	Transactions []string
+}
+
+func (b *Block) ToHeaderInfo() HeaderInfo {
+	txHashes := make([]string, len(b.Transactions))
+	for i, txHash := range b.Transactions {
+		txHashes[i] = txHash.String()
+	}
+	return HeaderInfo{
+		ChainId:      b.ChainId,
+		Hash:         b.Hash.String(),
+		Height:       b.Height,
+		Timestamp:    b.Timestamp,
+		PrevHash:     b.PrevHash.String(),
+		Validator:    b.Validator.String(),
+		Signature:    base64.StdEncoding.EncodeToString(b.Signature),
+		Transactions: txHashes,
+	}
+}
+
+func (hi *HeaderInfo) ToBlock() (*Block, error) {
+	sigBytes, err := base64.StdEncoding.DecodeString(hi.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	txHashes := make([]ecommon.Hash, len(hi.Transactions))
+	for i, txHash := range hi.Transactions {
+		txHashes[i] = ecommon.HexToHash(txHash)
+	}
+
+	return &Block{
+		ChainId:      hi.ChainId,
+		Hash:         ecommon.HexToHash(hi.Hash),
+		Height:       hi.Height,
+		Timestamp:    hi.Timestamp,
+		PrevHash:     ecommon.HexToHash(hi.PrevHash),
+		Validator:    ecommon.HexToAddress(hi.Validator),
+		Signature:    sigBytes,
+		Transactions: txHashes,
+	}, nil
+}
+
+type HeaderInfoList struct {
+	Count   uint64
+	Headers []HeaderInfo
+}
+
+// SyncStatus is the progress of a node catching up with the chain
+type SyncStatus struct {
+	Syncing bool
+	// "headers" while headers are downloaded and verified, "bodies" while blocks are downloaded and applied
+	Stage          string
+	StartingHeight uint64
+	CurrentHeight  uint64
+	// Highest verified header
+	HeadersHeight uint64
+	TargetHeight  uint64
+	// Blocks applied per second since the sync started
+	BlocksPerSecond float64
+	// Estimated seconds left, 0 when unknown
+	Eta int64
+}
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"dummy-chain/common"
+	"math/big"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// EthereumSigner accepts both EIP-155 protected transactions for this chain and unprotected legacy ones
+var EthereumSigner = ethtypes.LatestSignerForChainID(big.NewInt(common.DummyChainId))
+
+// DecodeEthereumTransaction turns a signed RLP legacy transaction into a chain transaction
+// Only plain value transfers are supported, there are no contracts on this chain
+func DecodeEthereumTransaction(raw []byte) (*Transaction, error) {
+	var ethTx ethtypes.Transaction
+	if err := ethTx.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	if ethTx.Type() != ethtypes.LegacyTxType {
+		return nil, errors.Errorf("unsupported transaction type %d", ethTx.Type())
+	}
+	if ethTx.To() == nil {
+		return nil, errors.New("contract creation is not supported")
+	}
+	if len(ethTx.Data()) > 0 {
+		return nil, errors.New("only value transfers are supported")
+	}
+
+	from, err := ethtypes.Sender(EthereumSigner, &ethTx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &Transaction{
+		From:  from,
+		Nonce: ethTx.Nonce(),
+		To:    *ethTx.To(),
+		Value: new(big.Int).Set(ethTx.Value()),
+		Raw:   raw,
+	}
+	tx.Hash = tx.GetHash()
+	return tx, nil
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"dummy-chain/common"
+	"fmt"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// RecoverSigner returns the address that produced the signature over the given hash
+func RecoverSigner(hash ecommon.Hash, signature []byte) (ecommon.Address, error) {
+	pubKeyBytes, err := crypto.Ecrecover(hash.Bytes(), signature)
+	if err != nil {
+		return ecommon.Address{}, err
+	}
+	pubKey, err := crypto.UnmarshalPubkey(pubKeyBytes)
+	if err != nil {
+		return ecommon.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
+// VerifySignature checks that the transaction hash was signed by the sender
+// Ethereum transactions are checked against their raw encoding instead
+func (tx *Transaction) VerifySignature() error {
+	if len(tx.Raw) > 0 {
+		decoded, err := DecodeEthereumTransaction(tx.Raw)
+		if err != nil {
+			return err
+		}
+		if decoded.From != tx.From || decoded.To != tx.To || decoded.Nonce != tx.Nonce || decoded.Value.Cmp(tx.Value) != 0 {
+			return common.ErrInvalidSignature
+		}
+		return nil
+	}
+
+	signer, err := RecoverSigner(tx.Hash, tx.Signature)
+	if err != nil {
+		return err
+	}
+	if signer != tx.From {
+		return common.ErrInvalidSignature
+	}
+	return nil
+}
+
+// Validate runs the stateless checks of a submitted transaction, errors wrap common.ErrInvalidTransaction
+func (tx *Transaction) Validate() error {
+	if tx.Value == nil || tx.Value.Sign() < 0 {
+		return fmt.Errorf("%w: invalid value", common.ErrInvalidTransaction)
+	}
+	if tx.Hash != tx.GetHash() {
+		return fmt.Errorf("%w: hash mismatch", common.ErrInvalidTransaction)
+	}
+	if err := tx.VerifySignature(); err != nil {
+		return fmt.Errorf("%w: %s", common.ErrInvalidTransaction, err.Error())
+	}
+	return nil
+}
+
+// VerifySignature checks that the block hash was signed by the validator
+func (b *Block) VerifySignature() error {
+	signer, err := RecoverSigner(b.Hash, b.Signature)
+	if err != nil {
+		return err
+	}
+	if signer != b.Validator {
+		return common.ErrInvalidSignature
+	}
+	return nil
+}
@@ -2,8 +2,10 @@ package types
 
 import (
 	"bytes"
+	"crypto/ecdsa"
 	"dummy-chain/common"
 	"encoding/base64"
+	"encoding/gob"
 	"fmt"
 	"math/big"
 
@@ -19,9 +21,65 @@ type Transaction struct {
 	To          ecommon.Address
 	Value       *big.Int
 	Signature   []byte
+	// Raw is the signed Ethereum RLP encoding of transactions sent through eth_sendRawTransaction
+	// When present it defines the hash and carries the signature instead of Signature
+	Raw []byte
+}
+
+// NewTransaction builds an unsigned transfer with its hash set
+func NewTransaction(from ecommon.Address, nonce uint64, to ecommon.Address, value *big.Int) *Transaction {
+	tx := &Transaction{
+		BlockHeight: 0,
+		From:        from,
+		Nonce:       nonce,
+		To:          to,
+		Value:       new(big.Int).Set(value),
+		Signature:   []byte{},
+	}
+	tx.Hash = tx.GetHash()
+	return tx
+}
+
+// Sign signs the hash with the key of the sender
+func (tx *Transaction) Sign(key *ecdsa.PrivateKey) error {
+	if crypto.PubkeyToAddress(key.PublicKey) != tx.From {
+		return common.ErrInvalidSignature
+	}
+	signature, err := crypto.Sign(tx.Hash[:], key)
+	if err != nil {
+		return err
+	}
+	tx.Signature = signature
+	return nil
+}
+
+// EncodeBase64 is the base64 gob encoding accepted by chain.SendTransaction
+func (tx *Transaction) EncodeBase64() (string, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(*tx); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecodeBase64Transaction is the inverse of EncodeBase64, it doesn't verify the transaction
+func DecodeBase64Transaction(base64Tx string) (*Transaction, error) {
+	data, err := base64.StdEncoding.DecodeString(base64Tx)
+	if err != nil {
+		return nil, err
+	}
+	var tx Transaction
+	if err = gob.NewDecoder(bytes.NewReader(data)).Decode(&tx); err != nil {
+		return nil, err
+	}
+	return &tx, nil
 }
 
 func (tx *Transaction) GetHash() ecommon.Hash {
+	if len(tx.Raw) > 0 {
+		return crypto.Keccak256Hash(tx.Raw)
+	}
+
 	buf := new(bytes.Buffer)
 	buf.Write(tx.From.Bytes())
 	buf.Write(common.Uint64ToBytes(tx.Nonce))
@@ -40,6 +98,7 @@ func (tx *Transaction) ToInfo() TransactionInfo {
 		To:          tx.To.String(),
 		Value:       new(big.Int).Set(tx.Value),
 		Signature:   base64.StdEncoding.EncodeToString(tx.Signature),
+		Raw:         base64.StdEncoding.EncodeToString(tx.Raw),
 	}
 }
 
@@ -76,6 +135,7 @@ type TransactionInfo struct {
 	To          string
 	Value       *big.Int
 	Signature   string
+	Raw         string `json:",omitempty"`
 }
 
 func (ti *TransactionInfo) ToTransaction() (*Transaction, error) {
@@ -83,6 +143,10 @@ func (ti *TransactionInfo) ToTransaction() (*Transaction, error) {
 	if err != nil {
 		return nil, err
 	}
+	rawBytes, err := base64.StdEncoding.DecodeString(ti.Raw)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Transaction{
 		Hash:        ecommon.HexToHash(ti.Hash),
@@ -92,5 +156,11 @@ func (ti *TransactionInfo) ToTransaction() (*Transaction, error) {
 		To:          ecommon.HexToAddress(ti.To),
 		Value:       ti.Value,
 		Signature:   sigBytes,
+		Raw:         rawBytes,
 	}, nil
 }
+
+type TransactionInfoList struct {
+	Count        uint64
+	Transactions []TransactionInfo
+}
@@ -0,0 +1,113 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNoAccount        = errors.New("no account in the keystore, create one with `account new` or `account import`")
+	ErrUnknownAccount   = errors.New("unknown account")
+	ErrAmbiguousAccount = errors.New("the keystore holds several accounts, set Base.Account in the config")
+	ErrWrongPassphrase  = errors.New("wrong passphrase")
+	ErrAccountExists    = errors.New("account already in the keystore")
+)
+
+// Keystore keeps the account keys of the data dir in Web3 Secret Storage v3 files, encrypted with scrypt and AES-128-CTR
+type Keystore struct {
+	store *keystore.KeyStore
+}
+
+func Open(dir string) *Keystore {
+	return &Keystore{
+		store: keystore.NewKeyStore(dir, keystore.StandardScryptN, keystore.StandardScryptP),
+	}
+}
+
+// Accounts returns the accounts of the keystore sorted by file name, that is by creation time
+func (k *Keystore) Accounts() []accounts.Account {
+	return k.store.Accounts()
+}
+
+// Find returns the account with the hex address, an empty address is only accepted when the keystore holds a single account
+func (k *Keystore) Find(address string) (accounts.Account, error) {
+	if address == "" {
+		all := k.store.Accounts()
+		switch len(all) {
+		case 0:
+			return accounts.Account{}, ErrNoAccount
+		case 1:
+			return all[0], nil
+		}
+		return accounts.Account{}, ErrAmbiguousAccount
+	}
+	if !ecommon.IsHexAddress(address) {
+		return accounts.Account{}, errors.Wrapf(ErrUnknownAccount, "invalid address %s", address)
+	}
+	account, err := k.store.Find(accounts.Account{Address: ecommon.HexToAddress(address)})
+	if err != nil {
+		return accounts.Account{}, errors.Wrap(ErrUnknownAccount, address)
+	}
+	return account, nil
+}
+
+// New generates a key and stores it encrypted with passphrase
+func (k *Keystore) New(passphrase string) (accounts.Account, error) {
+	return k.store.NewAccount(passphrase)
+}
+
+// ImportKey stores an existing private key encrypted with passphrase
+func (k *Keystore) ImportKey(key *ecdsa.PrivateKey, passphrase string) (accounts.Account, error) {
+	account, err := k.store.ImportECDSA(key, passphrase)
+	if errors.Is(err, keystore.ErrAccountAlreadyExists) {
+		return account, errors.Wrap(ErrAccountExists, account.Address.Hex())
+	}
+	return account, err
+}
+
+// ImportJson stores a key file of another keystore, it keeps its passphrase
+func (k *Keystore) ImportJson(keyJson []byte, passphrase string) (accounts.Account, error) {
+	account, err := k.store.Import(keyJson, passphrase, passphrase)
+	switch {
+	case errors.Is(err, keystore.ErrAccountAlreadyExists):
+		return account, errors.Wrap(ErrAccountExists, account.Address.Hex())
+	case errors.Is(err, keystore.ErrDecrypt):
+		return account, ErrWrongPassphrase
+	}
+	return account, err
+}
+
+// Export returns the key file of account after checking passphrase
+func (k *Keystore) Export(account accounts.Account, passphrase string) ([]byte, error) {
+	// Decrypting first tells a wrong passphrase apart from a broken file
+	if _, err := k.Unlock(account, passphrase); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(account.URL.Path)
+}
+
+// Unlock decrypts the private key of account
+func (k *Keystore) Unlock(account accounts.Account, passphrase string) (*ecdsa.PrivateKey, error) {
+	keyJson, err := os.ReadFile(account.URL.Path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(keyJson, passphrase)
+	if errors.Is(err, keystore.ErrDecrypt) {
+		return nil, ErrWrongPassphrase
+	} else if err != nil {
+		return nil, err
+	}
+	return key.PrivateKey, nil
+}
+
+// IsKeyJson tells a key file apart from a hex private key or a mnemonic
+func IsKeyJson(data []byte) bool {
+	return strings.HasPrefix(strings.TrimSpace(string(data)), "{")
+}
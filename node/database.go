@@ -0,0 +1,71 @@
+package node
+
+import (
+	"dummy-chain/common"
+	"dummy-chain/storage"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// VerifyChain runs the offline integrity checks over the local database
+func (node *Node) VerifyChain() (*storage.VerifyReport, error) {
+	node.lock.Lock()
+	defer node.lock.Unlock()
+
+	return node.storage.VerifyChain()
+}
+
+// Reindex rebuilds the accounts and height index from the stored blocks
+func (node *Node) Reindex(progress storage.ReindexProgress) error {
+	node.lock.Lock()
+	defer node.lock.Unlock()
+
+	return node.storage.Reindex(progress)
+}
+
+func (node *Node) backupDir() string {
+	return filepath.Join(node.globalConfig.GetDataPath(), common.DefaultBackupDir)
+}
+
+// Backup writes a full snapshot of the database into the backup directory and returns its path
+func (node *Node) Backup(name string) (string, error) {
+	path, err := storage.BackupFilePath(node.backupDir(), name)
+	if err != nil {
+		return "", err
+	}
+	if _, err = node.storage.BackupToFile(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Restore loads a backup file into the local database
+// The data dir is already locked by the node, so a running instance can never be overwritten
+// The whole backup is read once before the database is touched, a corrupt file must not leave it dropped
+// A backup below minHeight is refused, a validator could not sign the blocks it already signed above it again
+func (node *Node) Restore(path string, overwrite bool, minHeight uint64) error {
+	node.lock.Lock()
+	defer node.lock.Unlock()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	height, err := storage.BackupHeight(file)
+	if err != nil {
+		return errors.Wrapf(err, "invalid backup %s", path)
+	}
+	if height < minHeight {
+		return fmt.Errorf("%w: the backup ends at block %d and block %d was signed", common.ErrBackupBehindSigner, height, minHeight)
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return node.storage.Restore(file, overwrite)
+}
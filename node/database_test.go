@@ -0,0 +1,45 @@
+package node
+
+import (
+	"dummy-chain/common/config"
+	"dummy-chain/storage"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRestoreInvalidBackupKeepsDatabase(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	db, err := storage.NewBadgerDb("test", config.CacheConfig{Blocks: 16, Transactions: 16, Accounts: 16, Heights: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	headers := testChain(3)
+	for _, block := range headers {
+		if err = db.SetBlock(block, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	node := &Node{storage: db}
+
+	backup := filepath.Join(t.TempDir(), "chain.bak")
+	if _, err = db.BackupToFile(backup); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	truncated := filepath.Join(t.TempDir(), "truncated.bak")
+	if err = os.WriteFile(truncated, data[:len(data)/2], 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = node.Restore(truncated, true, 0); err == nil {
+		t.Fatal("a truncated backup must be refused")
+	}
+	if height, errHeight := db.GetHeight(); errHeight != nil || height != 3 {
+		t.Fatalf("the database must be left untouched, got height %d: %v", height, errHeight)
+	}
+}
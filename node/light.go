@@ -0,0 +1,174 @@
+package node
+
+import (
+	"context"
+	"dummy-chain/common/types"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrAccountMismatch is returned on a light node when the validator endpoints report different accounts
+	ErrAccountMismatch = errors.New("the endpoints disagree on the account")
+	// ErrUnprovenTransaction is returned on a light node when a transaction doesn't match the stored headers
+	ErrUnprovenTransaction = errors.New("the transaction doesn't match the stored headers")
+	// ErrTooFewAnswers is returned on a light node when less than minAccountAnswers endpoints reported an account
+	ErrTooFewAnswers = errors.New("too few endpoints answered to cross-check the account")
+
+	errHeightsDiffer = errors.New("the endpoints are at different heights")
+)
+
+const (
+	// Endpoints that must report the same account at the same height for a light node to trust it
+	minAccountAnswers = 2
+	// Rounds of queries before giving up on endpoints that keep reporting different heights
+	accountAttempts   = 3
+	accountRetryDelay = time.Second
+)
+
+// GetAccount reads the account from the database, a light node asks every validator endpoint instead
+func (node *Node) GetAccount(ctx context.Context, address ecommon.Address) (*types.Account, error) {
+	if !node.light {
+		return node.storage.GetAccount(address)
+	}
+	return node.fetchAccount(ctx, address)
+}
+
+// fetchAccount asks Url and every fallback url at once, at least minAccountAnswers must answer at the same height and agree
+// The validator doesn't prove its state, so cross-checking several endpoints is what protects a light node
+func (node *Node) fetchAccount(ctx context.Context, address ecommon.Address) (*types.Account, error) {
+	if len(node.syncSources) < minAccountAnswers {
+		return nil, fmt.Errorf("%w: a light node needs FallbackUrls besides Url", ErrTooFewAnswers)
+	}
+	for attempt := 1; ; attempt++ {
+		account, err := node.crossCheckAccount(ctx, address)
+		if !errors.Is(err, errHeightsDiffer) || attempt == accountAttempts {
+			return account, err
+		}
+		// A block reached some endpoints first, they catch up in a moment
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(accountRetryDelay):
+		}
+	}
+}
+
+// accountAnswer is the account an endpoint reported and the height it was read at
+type accountAnswer struct {
+	account *types.Account
+	height  uint64
+	err     error
+}
+
+// crossCheckAccount runs one round of fetchAccount, errHeightsDiffer means it is worth another round
+func (node *Node) crossCheckAccount(ctx context.Context, address ecommon.Address) (*types.Account, error) {
+	answers := make([]accountAnswer, len(node.syncSources))
+	var wg sync.WaitGroup
+	for i, source := range node.syncSources {
+		wg.Add(1)
+		go func(i int, source *syncSource) {
+			defer wg.Done()
+			answers[i] = queryAccount(ctx, source, address)
+		}(i, source)
+	}
+	wg.Wait()
+
+	var result *accountAnswer
+	var resultUrl string
+	answered := 0
+	var firstErr error
+	for i := range answers {
+		answer := &answers[i]
+		url := node.syncSources[i].url
+		if answer.err != nil {
+			node.logger.Debugf("Failed to get account %s from %s: %s", address.Hex(), url, answer.err.Error())
+			if firstErr == nil || errors.Is(answer.err, errHeightsDiffer) {
+				firstErr = answer.err
+			}
+			continue
+		}
+		answered++
+		switch {
+		case result == nil:
+			result, resultUrl = answer, url
+		case answer.height != result.height:
+			return nil, fmt.Errorf("%w: %s is at block %d and %s at block %d", errHeightsDiffer, resultUrl, result.height, url, answer.height)
+		case answer.account.Nonce != result.account.Nonce || answer.account.Balance.Cmp(result.account.Balance) != 0:
+			return nil, fmt.Errorf("%w: %s and %s at block %d", ErrAccountMismatch, resultUrl, url, answer.height)
+		}
+	}
+	if answered < minAccountAnswers {
+		if errors.Is(firstErr, errHeightsDiffer) {
+			return nil, firstErr
+		}
+		return nil, fmt.Errorf("%w: %d of %d for account %s: %s", ErrTooFewAnswers, answered, len(answers), address.Hex(), firstErr.Error())
+	}
+	return result.account, nil
+}
+
+// queryAccount reads the account between two height reads, so it is known to be the account at that height
+func queryAccount(ctx context.Context, source *syncSource, address ecommon.Address) accountAnswer {
+	before, err := source.client.GetCurrentBlockHeight(ctx)
+	if err != nil {
+		return accountAnswer{err: err}
+	}
+	info, err := source.client.GetAccountInfo(ctx, address)
+	if err != nil {
+		return accountAnswer{err: err}
+	}
+	if info.BalanceRaw == nil || ecommon.HexToAddress(info.Address) != address {
+		return accountAnswer{err: errors.Errorf("invalid account returned by %s", source.url)}
+	}
+	after, err := source.client.GetCurrentBlockHeight(ctx)
+	if err != nil {
+		return accountAnswer{err: err}
+	}
+	if after != before {
+		return accountAnswer{err: fmt.Errorf("%w: %s moved from block %d to %d", errHeightsDiffer, source.url, before, after)}
+	}
+	return accountAnswer{
+		account: &types.Account{Address: address, Nonce: info.Nonce, Balance: info.BalanceRaw},
+		height:  after,
+	}
+}
+
+// GetTransaction reads the transaction from the database, a light node downloads it
+// and checks it against the signed header of its block
+func (node *Node) GetTransaction(ctx context.Context, hash ecommon.Hash) (*types.Transaction, error) {
+	if !node.light {
+		return node.storage.GetTransaction(hash)
+	}
+
+	info, err := node.rpcClient.GetTransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := info.ToTransaction()
+	if err != nil {
+		return nil, err
+	}
+	if tx.Hash != hash || tx.GetHash() != hash {
+		return nil, fmt.Errorf("%w: hash mismatch", ErrUnprovenTransaction)
+	}
+	// Genesis transactions aren't signed, the genesis header is built locally
+	if tx.BlockHeight > 0 {
+		if err = tx.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnprovenTransaction, err.Error())
+		}
+	}
+
+	header, err := node.storage.GetBlockByHeight(tx.BlockHeight)
+	if err != nil {
+		return nil, errors.Wrapf(err, "header %d isn't synced", tx.BlockHeight)
+	}
+	if !slices.Contains(header.Transactions, hash) {
+		return nil, fmt.Errorf("%w: not listed in block %d", ErrUnprovenTransaction, tx.BlockHeight)
+	}
+	return tx, nil
+}
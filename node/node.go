@@ -1,17 +1,15 @@
 package node
 
 import (
-	"bytes"
 	"context"
-	"crypto/ecdsa"
 	"dummy-chain/common"
 	"dummy-chain/common/config"
 	"dummy-chain/common/types"
 	"dummy-chain/metadata"
+	"dummy-chain/p2p"
 	"dummy-chain/rpc"
+	"dummy-chain/signer"
 	"dummy-chain/storage"
-	"encoding/base64"
-	"encoding/gob"
 	"math/big"
 	"math/rand/v2"
 	"os"
@@ -20,77 +18,119 @@ import (
 	"time"
 
 	ecommon "github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
 	"github.com/prometheus/tsdb/fileutil"
-	"github.com/tyler-smith/go-bip32"
-	"github.com/tyler-smith/go-bip39"
 	"go.uber.org/zap"
 )
 
+// Time given to running requests when the node stops
+const rpcShutdownTimeout = 5 * time.Second
+
 type Node struct {
 	globalConfig *config.GlobalConfig
 	logger       *zap.SugaredLogger
 
-	// Private key and address of the user
-	privateKey *ecdsa.PrivateKey
-	address    *ecommon.Address
+	// Signer of the blocks and transactions of the user and its address
+	signer  signer.Signer
+	address *ecommon.Address
 
-	rpcServer *rpc.Server
-	rpcClient *rpc.Client
-	memPool   *rpc.MemoryPool
-	storage   *storage.BadgerDb
+	rpcServer  *rpc.Server
+	grpcServer *rpc.GrpcServer
+	rpcClient  *rpc.Client
+	p2pServer  *p2p.Server
+	memPool    *rpc.MemoryPool
+	eventBus   *rpc.EventBus
+	storage    *storage.BadgerDb
+	// Light nodes only store the block headers
+	light bool
 
 	// Channel to wait for termination notifications
 	stopChan chan os.Signal
 	lock     sync.RWMutex
+	// Serializes the blocks applied from the validator and from peers
+	applyLock sync.Mutex
+	// Validator of block 1 at Url, fetched once when P2p.ValidatorAddress isn't configured
+	validator     *ecommon.Address
+	validatorLock sync.Mutex
+	// Endpoints the block bodies are downloaded from, only one sync runs at a time
+	syncSources []*syncSource
+	syncLock    sync.Mutex
+	syncTracker syncTracker
 	// Prevents concurrent use of instance directory
 	dataDirLock fileutil.Releaser
 }
 
-func NewNode(globalConfig *config.GlobalConfig, logger *zap.Logger) (*Node, error) {
+// NewNode creates a node signing with nodeSigner, nil for the commands that don't start it
+func NewNode(globalConfig *config.GlobalConfig, nodeSigner signer.Signer, logger *zap.Logger) (*Node, error) {
 	var err error
 
+	eventBus := rpc.NewEventBus()
 	node := &Node{
 		globalConfig: globalConfig,
-		memPool:      rpc.NewMemoryPool(),
+		memPool:      rpc.NewMemoryPool(eventBus),
+		eventBus:     eventBus,
 		logger:       logger.Sugar(),
 		stopChan:     make(chan os.Signal, 1),
+		light:        globalConfig.Light,
+	}
+	if node.light && metadata.Role == common.ValidatorRole {
+		return nil, errors.New("Light mode is only available to clients")
 	}
 
 	if err = node.openDataDir(); err != nil {
 		return nil, err
 	}
 
-	node.storage, err = storage.NewBadgerDb("chain")
+	node.storage, err = storage.NewBadgerDb("chain", globalConfig.CacheConfig)
 	if err != nil {
 		return nil, err
 	}
 
+	// The admin RPCs manage the peers of the validator
+	var peers rpc.PeerManager
+	if globalConfig.P2pConfig.Enabled() {
+		node.p2pServer, err = node.newP2pServer()
+		if err != nil {
+			return nil, err
+		}
+		peers = node.p2pServer
+	}
+
 	// Only the syncer is the server and the source of truth, other are clients
 	// All RPCs will be sent to him
 	if metadata.Role == common.ValidatorRole {
-		node.rpcServer, err = rpc.NewServer(node.storage, node.memPool)
+		// One limiter for both servers, a client has the same budget over HTTP and gRPC
+		limiter, errLimiter := rpc.NewRateLimiter(globalConfig.RateLimitConfig)
+		if errLimiter != nil {
+			return nil, errLimiter
+		}
+		node.rpcServer, err = rpc.NewServer(node.storage, node.memPool, node.eventBus, node.backupDir(), peers, globalConfig.RpcConfig, globalConfig.AuthConfig, limiter)
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		if globalConfig.AccountIndex == 0 {
-			return nil, errors.New("Account index must be greater than 0")
+		if globalConfig.GrpcConfig.Address != "" {
+			node.grpcServer, err = rpc.NewGrpcServer(node.storage, node.memPool, node.eventBus, globalConfig.GrpcConfig, globalConfig.AuthConfig, limiter)
+			if err != nil {
+				return nil, err
+			}
 		}
-		node.rpcClient, err = rpc.NewClient(globalConfig.Url)
+	} else {
+		node.rpcClient, err = rpc.NewClient(globalConfig.Url,
+			rpc.WithFallbackUrls(globalConfig.FallbackUrls...),
+			rpc.WithAuthToken(globalConfig.Token),
+		)
 		if err != nil {
 			return nil, err
 		}
+		if node.syncSources, err = newSyncSources(globalConfig.BaseConfig); err != nil {
+			return nil, err
+		}
 	}
 
-	seed := bip39.NewSeed(globalConfig.GetMnemonic(), "")
-	masterKey, err := bip32.NewMasterKey(seed)
-	if err != nil {
-		return nil, err
+	if nodeSigner != nil {
+		address := nodeSigner.Address()
+		node.signer, node.address = nodeSigner, &address
 	}
-	node.privateKey, node.address, err = common.DeriveKey(masterKey, globalConfig.AccountIndex)
-
 	return node, nil
 }
 
@@ -98,10 +138,19 @@ func (node *Node) Start(shouldSync bool) error {
 	node.lock.Lock()
 	defer node.lock.Unlock()
 
-	if errStart := node.storage.Start(); errStart != nil {
+	if node.signer == nil {
+		return errors.New("No account unlocked")
+	}
+	if errStart := node.storage.Start(node.light); errStart != nil {
 		return errStart
 	}
 
+	if node.p2pServer != nil {
+		if errStart := node.p2pServer.Start(); errStart != nil {
+			return errStart
+		}
+	}
+
 	if metadata.Role == common.ValidatorRole {
 		go func() {
 			if errStart := node.rpcServer.Start(); errStart != nil {
@@ -109,58 +158,38 @@ func (node *Node) Start(shouldSync bool) error {
 			}
 		}()
 
+		if node.grpcServer != nil {
+			go func() {
+				if errStart := node.grpcServer.Start(node.globalConfig.GrpcConfig.Address); errStart != nil {
+					node.logger.Debugf("grpc server start error: %v", errStart)
+				}
+			}()
+		}
+
 		go node.CreateBlocks(context.Background())
 	}
 
 	if shouldSync && metadata.Role == common.ClientRole {
-		if errStart := node.Sync(); errStart != nil {
-			return errStart
+		if errStart := node.Sync(context.Background()); errStart != nil {
+			if node.p2pServer == nil {
+				return errStart
+			}
+			// Peers can still bring the chain up to date
+			node.logger.Debugf("Failed to sync from the validator: %s", errStart.Error())
 		}
 
 		go node.FetchBlocks(context.Background())
 	}
 
-	/// Print own address
-	mnemonic := "margin bounce nominee submit pupil duty bird daughter hotel onion wave write"
+	common.GlobalLogger.Debugf("Address: %s", node.address.Hex())
 
-	seed := bip39.NewSeed(mnemonic, "")
-	masterKey, err := bip32.NewMasterKey(seed)
-	if err != nil {
-		return err
-	}
-
-	purposeKey, err := masterKey.NewChildKey(bip32.FirstHardenedChild + 44)
-	if err != nil {
-		return err
-	}
-	coinTypeKey, err := purposeKey.NewChildKey(bip32.FirstHardenedChild + 60)
-	if err != nil {
-		return err
-	}
-	accountKey, err := coinTypeKey.NewChildKey(bip32.FirstHardenedChild)
-	if err != nil {
-		return err
-	}
-	changeKey, err := accountKey.NewChildKey(0)
-	if err != nil {
-		return err
-	}
-
-	addressKey, errChild := changeKey.NewChildKey(node.globalConfig.AccountIndex)
-	if errChild != nil {
-		return errChild
-	}
-
-	privateKey, errPrivateKey := crypto.ToECDSA(addressKey.Key)
-	if errPrivateKey != nil {
-		return errPrivateKey
-	}
-
-	address := crypto.PubkeyToAddress(privateKey.PublicKey)
-	common.GlobalLogger.Debugf("%d. Address: %s", node.globalConfig.AccountIndex, address.Hex())
-
-	account, err := node.storage.GetAccount(address)
+	account, err := node.GetAccount(context.Background(), *node.address)
 	if err != nil {
+		if node.light {
+			// The validators may be unreachable, a light node still follows its peers
+			common.GlobalLogger.Debugf("Failed to get my balance: %s", err.Error())
+			return nil
+		}
 		return err
 	}
 	common.GlobalLogger.Debugf("My balance is: %s and my nonce is: %d",
@@ -175,6 +204,31 @@ func (node *Node) Stop() error {
 	defer close(node.stopChan)
 	node.logger.Info("stopping node ...")
 
+	if node.p2pServer != nil {
+		node.p2pServer.Stop()
+	}
+	if node.rpcServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), rpcShutdownTimeout)
+		if err := node.rpcServer.Stop(ctx); err != nil {
+			node.logger.Error("failed to stop rpc server", zap.String("reason", err.Error()))
+		}
+		cancel()
+	}
+	if node.grpcServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), rpcShutdownTimeout)
+		node.grpcServer.Stop(ctx)
+		cancel()
+	}
+	if node.rpcClient != nil {
+		node.rpcClient.Close()
+	}
+	for _, source := range node.syncSources {
+		source.client.Close()
+	}
+	if err := node.storage.Close(); err != nil {
+		node.logger.Error("failed to close storage", zap.String("reason", err.Error()))
+	}
+
 	// Release instance directory lock.
 	node.closeDataDir()
 	return nil
@@ -201,43 +255,27 @@ func (node *Node) CreateBlocks(ctx context.Context) {
 				continue
 			}
 
-			block := &types.Block{
-				ChainId:   common.DummyChainId,
-				Height:    currentBlockHeight + 1,
-				Timestamp: time.Now().Unix(),
-				PrevHash:  prevBlock.Hash,
-				Validator: *node.address,
-			}
-
-			txs := node.memPool.GetMemPool()
-
-			// Only for testing purposes
-			//if len(txs) == 0 {
-			//	txs = node.GenerateRandomTestTransactions()
-			//}
-
-			var goodTxs []*types.Transaction
-			if len(txs) > 0 {
-				goodTxs = node.VerifyTransactions(txs)
-				for _, tx := range goodTxs {
-					tx.BlockHeight = block.Height
-					block.Transactions = append(block.Transactions, tx.Hash)
-				}
-			}
-
-			block.Hash = block.GetHash()
-			block.Signature, err = crypto.Sign(block.Hash[:], node.privateKey)
+			created, txs, err := node.nextBlock(ctx, prevBlock)
 			if err != nil {
-				node.logger.Debugf("Failed to sign block: %s", err.Error())
+				if errors.Is(err, signer.ErrSlashable) {
+					node.logger.Errorf("Failed to sign block: %s", err.Error())
+				} else {
+					node.logger.Debugf("Failed to sign block: %s", err.Error())
+				}
 				continue
 			}
+			block := created.Block
 
-			if errSet := node.storage.SetBlock(block, goodTxs); errSet != nil {
+			// A block that can't be stored stays pending and is signed again on the next tick
+			if errSet := node.storage.SetBlock(block, created.Transactions); errSet != nil {
 				node.logger.Debugf("Failed to set block: %s", errSet.Error())
 				continue
-			} else {
-				// Only now we remove the transactions from the mempool
-				node.memPool.RemoveTxs(txs)
+			}
+			// Only now we remove the transactions from the mempool
+			node.memPool.RemoveTxs(txs)
+			node.eventBus.PublishBlock(created.ToInfo())
+			if node.p2pServer != nil {
+				node.p2pServer.BroadcastBlock(created)
 			}
 
 			node.logger.Debugf("Created a new block: %s", block.String())
@@ -245,53 +283,93 @@ func (node *Node) CreateBlocks(ctx context.Context) {
 	}
 }
 
-func (node *Node) Sync() error {
-	for {
-		currentHeight, err := node.storage.GetHeight()
-		if err != nil {
-			return err
-		}
+// nextBlock returns the signed block after prevBlock and the transactions to remove from the mempool once it is stored
+// A pending block left by a failed store or a crash is signed again as is, the signer refuses any other block at its height
+func (node *Node) nextBlock(ctx context.Context, prevBlock *types.Block) (*storage.BlockWithTransactions, []*types.Transaction, error) {
+	pending, err := node.storage.GetPendingBlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	if pending == nil || pending.Block.PrevHash != prevBlock.Hash {
+		return node.createBlock(ctx, prevBlock)
+	}
 
-		list, err := node.rpcClient.GetBlocksInterval(currentHeight+1, currentHeight+10)
-		if err != nil {
-			return err
-		} else if list.Count == 0 {
-			break
-		}
-		node.logger.Debugf("Syncing block height: %d. Synced %d blocks", currentHeight, list.Count)
-		for _, blockInfo := range list.Blocks {
-			block, errTo := blockInfo.ToBlock()
-			if errTo != nil {
-				return errTo
-			}
-			//node.logger.Debugf("Adding block: %s", block.Hash.String())
-			txs := make([]*types.Transaction, 0)
-			for _, txInfo := range blockInfo.Transactions {
-				tx, errTo := txInfo.ToTransaction()
-				if errTo != nil {
-					return errTo
-				}
-				txs = append(txs, tx)
-			}
-			if errSet := node.storage.SetBlock(block, txs); errSet != nil {
-				return errSet
-			}
+	if pending.Block.Signature, err = node.signer.SignBlock(ctx, pending.Block); err != nil {
+		return nil, nil, err
+	}
+	return pending, pending.Transactions, nil
+}
+
+// createBlock builds and signs the block after prevBlock with the valid transactions of the mempool
+// The block is kept pending before it is signed, see nextBlock
+// It also returns every transaction taken from the mempool, to remove them once the block is stored
+func (node *Node) createBlock(ctx context.Context, prevBlock *types.Block) (*storage.BlockWithTransactions, []*types.Transaction, error) {
+	block := &types.Block{
+		ChainId:   common.DummyChainId,
+		Height:    prevBlock.Height + 1,
+		Timestamp: time.Now().Unix(),
+		PrevHash:  prevBlock.Hash,
+		Validator: *node.address,
+	}
+
+	txs := node.memPool.GetMemPool()
+
+	// Only for testing purposes
+	//if len(txs) == 0 {
+	//	txs = node.GenerateRandomTestTransactions()
+	//}
+
+	var goodTxs []*types.Transaction
+	if len(txs) > 0 {
+		goodTxs = node.VerifyTransactions(txs)
+		for _, tx := range goodTxs {
+			tx.BlockHeight = block.Height
+			block.Transactions = append(block.Transactions, tx.Hash)
 		}
-		time.Sleep(200 * time.Millisecond)
 	}
-	return nil
+
+	block.Hash = block.GetHash()
+	created := &storage.BlockWithTransactions{Block: block, Transactions: goodTxs}
+	if err := node.storage.SetPendingBlock(created); err != nil {
+		return nil, nil, err
+	}
+
+	var err error
+	if block.Signature, err = node.signer.SignBlock(ctx, block); err != nil {
+		return nil, nil, err
+	}
+	return created, txs, nil
+}
+
+// applyBlockInfo applies a block received from the validator, a block a peer delivered first is skipped
+func (node *Node) applyBlockInfo(blockInfo types.BlockInfo) error {
+	block, err := blockInfoToBlock(blockInfo)
+	if err != nil {
+		return err
+	}
+	err = node.applyBlock(block)
+	if errors.Is(err, p2p.ErrKnownBlock) {
+		return nil
+	}
+	return err
 }
 
+// FetchBlocks follows the validator through a newBlocks subscription
+// Whenever the subscription can't be opened or drops, it polls once and retries after a block time
 func (node *Node) FetchBlocks(ctx context.Context) {
-	ticker := time.NewTicker(5 * time.Second)
+	ticker := time.NewTicker(common.BlockTime)
 	defer ticker.Stop()
 
 	for {
+		if errFollow := node.followBlocks(ctx); errFollow != nil {
+			node.logger.Debugf("Block subscription ended: %s", errFollow.Error())
+		}
+
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			errSync := node.Sync()
+			errSync := node.Sync(ctx)
 			if errSync != nil {
 				node.logger.Debugf("Failed to sync blocks: %s", errSync.Error())
 			}
@@ -299,6 +377,44 @@ func (node *Node) FetchBlocks(ctx context.Context) {
 	}
 }
 
+// followBlocks applies pushed blocks as they arrive, falling back to Sync when one was missed
+func (node *Node) followBlocks(ctx context.Context) error {
+	sub, err := node.rpcClient.Subscribe(ctx, rpc.NewBlocksTopic)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	// Catch up on anything created before the subscription was active
+	if err = node.Sync(ctx); err != nil {
+		return err
+	}
+
+	for {
+		var blockInfo types.BlockInfo
+		if err = sub.Next(&blockInfo); err != nil {
+			return err
+		}
+
+		currentHeight, err := node.storage.GetHeight()
+		if err != nil {
+			return err
+		}
+		switch {
+		case blockInfo.Height <= currentHeight:
+			continue
+		case blockInfo.Height == currentHeight+1:
+			if err = node.applyBlockInfo(blockInfo); err != nil {
+				return err
+			}
+		default:
+			if err = node.Sync(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 // VerifyTransactions
 // It will simulate  the execution of all transactions
 // Return a map of bad transaction to ignore them when adding to the block
@@ -310,21 +426,10 @@ func (node *Node) VerifyTransactions(txs []*types.Transaction) []*types.Transact
 	for _, tx := range txs {
 		//node.logger.Debugf("Verifying transaction: %s", tx.String())
 
-		// Check signature
-		if pubKeyBytes, err := crypto.Ecrecover(tx.Hash.Bytes(), tx.Signature); err != nil {
-			node.logger.Debugf("Failed to recover tx signature: %s", err.Error())
+		// Check signature, this also covers transactions sent through eth_sendRawTransaction
+		if err := tx.VerifySignature(); err != nil {
+			node.logger.Debugf("Invalid signature for tx %s: %s", tx.Hash, err.Error())
 			continue
-		} else {
-			if recoveredPubKey, err := crypto.UnmarshalPubkey(pubKeyBytes); err != nil {
-				node.logger.Debugf("Failed to unmarshal pubkey: %s", err.Error())
-				continue
-			} else {
-				recoveredAddress := crypto.PubkeyToAddress(*recoveredPubKey)
-				if !reflect.DeepEqual(recoveredAddress, tx.From) {
-					node.logger.Debugf("Signer %s is not the sender %s", recoveredAddress, tx.From)
-					continue
-				}
-			}
 		}
 
 		// Check that sender is different than the receiver
@@ -403,7 +508,7 @@ func (node *Node) GenerateRandomTestTransactions() []*types.Transaction {
 			Value: new(big.Int).Set(value),
 		}
 		tx.Hash = tx.GetHash()
-		tx.Signature, err = crypto.Sign(tx.Hash[:], node.privateKey)
+		tx.Signature, err = node.signer.SignTransaction(context.Background(), tx)
 		if err != nil {
 			node.logger.Debugf("Failed to sign tx: %s", err.Error())
 			continue
@@ -417,39 +522,62 @@ func (node *Node) GenerateRandomTestTransactions() []*types.Transaction {
 	return txs
 }
 
+// SendTransaction builds, signs and broadcasts a transfer of value coins to to
 func (node *Node) SendTransaction(to string, value string) error {
 	valueBig, err := common.ParseToBigInt(value)
 	if err != nil {
 		return err
 	}
-
-	account, err := node.storage.GetAccount(*node.address)
+	tx, err := node.BuildTransaction(ecommon.HexToAddress(to), valueBig)
 	if err != nil {
 		return err
 	}
+	if err = node.SignTransaction(tx); err != nil {
+		return err
+	}
+	return node.BroadcastTransaction(tx)
+}
 
-	if valueBig.Cmp(account.Balance) > 0 {
-		return common.ErrNotEnoughBalanceUser
+// BuildTransaction prepares an unsigned transfer of value from the account of the node with its next nonce
+func (node *Node) BuildTransaction(to ecommon.Address, value *big.Int) (*types.Transaction, error) {
+	account, err := node.GetAccount(context.Background(), *node.address)
+	if err != nil {
+		return nil, err
 	}
 
-	tx := &types.Transaction{
-		BlockHeight: 0,
-		From:        *node.address,
-		Nonce:       account.Nonce,
-		To:          ecommon.HexToAddress(to),
-		Value:       new(big.Int).Set(valueBig),
-		Signature:   []byte{},
+	if value.Cmp(account.Balance) > 0 {
+		return nil, common.ErrNotEnoughBalanceUser
 	}
+	return types.NewTransaction(*node.address, account.Nonce, to, value), nil
+}
 
-	tx.Hash = tx.GetHash()
-	tx.Signature, err = crypto.Sign(tx.Hash[:], node.privateKey)
+// SignTransaction signs tx with the signer of the node
+func (node *Node) SignTransaction(tx *types.Transaction) error {
+	signature, err := node.signer.SignTransaction(context.Background(), tx)
 	if err != nil {
 		return err
 	}
-	var txBuf bytes.Buffer
-	if err := gob.NewEncoder(&txBuf).Encode(*tx); err != nil {
+	tx.Signature = signature
+	return nil
+}
+
+// BroadcastTransaction submits a signed transaction to the validator
+// When it can't be reached the transaction is relayed to the peers, which carry it to the validator
+func (node *Node) BroadcastTransaction(tx *types.Transaction) error {
+	err := node.rpcClient.SendTransaction(context.Background(), tx)
+	if node.p2pServer == nil {
 		return err
 	}
-	base64Tx := base64.StdEncoding.EncodeToString(txBuf.Bytes())
-	return node.rpcClient.SendTransaction(base64Tx)
+	var rpcErr *rpc.Error
+	if err != nil && !errors.As(err, &rpcErr) {
+		// The validator can't be reached, a node started just to send hasn't met its peers yet
+		node.p2pServer.WaitForPeers(peerWaitTimeout)
+	}
+	relayed := node.p2pServer.BroadcastTransaction(tx)
+	if err != nil && relayed > 0 && !errors.As(err, &rpcErr) {
+		// The peers carry the transaction to the validator
+		node.logger.Debugf("Failed to send the transaction to the validator, relayed to %d peers: %s", relayed, err.Error())
+		return nil
+	}
+	return err
 }
@@ -0,0 +1,230 @@
+package node
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"dummy-chain/common"
+	"dummy-chain/common/types"
+	"dummy-chain/metadata"
+	"dummy-chain/p2p"
+	"dummy-chain/rpc"
+	"dummy-chain/storage"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+const (
+	// File of the data dir holding the hex private key that identifies the node to its peers
+	nodeKeyFile = "nodekey"
+	// File of the data dir holding the known peers, their scores and the banned nodes
+	peerStoreFile = "peers.json"
+)
+
+// How long a transaction waits for a peer to relay it when the validator can't be reached
+const peerWaitTimeout = 10 * time.Second
+
+// newP2pServer creates the gossip server with the node key and the peer store of the data dir
+func (node *Node) newP2pServer() (*p2p.Server, error) {
+	nodeKey, err := node.loadNodeKey()
+	if err != nil {
+		return nil, err
+	}
+	storePath := filepath.Join(node.globalConfig.GetDataPath(), peerStoreFile)
+	return p2p.NewServer(nodeKey, node.globalConfig.P2pConfig, storePath, &p2pBackend{node: node})
+}
+
+// loadNodeKey reads the node key of the data dir, creating it on first use
+// It is separate from the account key so that nodes sharing an account are still distinct peers
+func (node *Node) loadNodeKey() (*ecdsa.PrivateKey, error) {
+	path := filepath.Join(node.globalConfig.GetDataPath(), nodeKeyFile)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return crypto.HexToECDSA(strings.TrimSpace(string(data)))
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(path, []byte(hex.EncodeToString(crypto.FromECDSA(key))), 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// p2pBackend exposes the chain of the node to the gossip server
+type p2pBackend struct {
+	node *Node
+}
+
+func (b *p2pBackend) Height() (uint64, error) {
+	return b.node.storage.GetHeight()
+}
+
+// GetBlocks returns nothing on a light node, it doesn't have the transactions of its blocks
+func (b *p2pBackend) GetBlocks(from, to uint64) ([]*storage.BlockWithTransactions, error) {
+	if b.node.light {
+		return nil, nil
+	}
+	return b.node.storage.GetBlockRange(from, to)
+}
+
+// HandleTransaction relays valid transactions, the validator also adds them to its mempool
+func (b *p2pBackend) HandleTransaction(tx *types.Transaction) error {
+	if err := tx.Validate(); err != nil {
+		return err
+	}
+	if metadata.Role == common.ValidatorRole {
+		return rpc.AddToMemPool(b.node.storage, b.node.memPool, tx)
+	}
+	return nil
+}
+
+func (b *p2pBackend) HandleBlock(block *storage.BlockWithTransactions) error {
+	if metadata.Role == common.ValidatorRole {
+		// The validator only keeps the blocks it created
+		return b.node.checkKnownBlock(block.Block)
+	}
+	return b.node.applyBlock(block)
+}
+
+// checkKnownBlock returns p2p.ErrKnownBlock when block is already stored and an error otherwise
+func (node *Node) checkKnownBlock(block *types.Block) error {
+	stored, err := node.storage.GetBlockByHeight(block.Height)
+	if err != nil {
+		return fmt.Errorf("%w: unknown block %d", p2p.ErrInvalidBlock, block.Height)
+	}
+	if stored.Hash != block.Hash {
+		return fmt.Errorf("%w: block %d conflicts with the stored one", p2p.ErrInvalidBlock, block.Height)
+	}
+	return p2p.ErrKnownBlock
+}
+
+// applyBlock verifies and stores the next block of the chain, from the validator or from a peer
+// A light node only stores the header once the transactions were checked against it
+// Blocks already stored return p2p.ErrKnownBlock and blocks past the next height p2p.ErrMissingParent
+func (node *Node) applyBlock(block *storage.BlockWithTransactions) error {
+	node.applyLock.Lock()
+	defer node.applyLock.Unlock()
+
+	height, err := node.storage.GetHeight()
+	if err != nil {
+		return err
+	}
+	if block.Block.Height <= height {
+		return node.checkKnownBlock(block.Block)
+	} else if block.Block.Height > height+1 {
+		return p2p.ErrMissingParent
+	}
+
+	parent, err := node.storage.GetBlockByHeight(height)
+	if err != nil {
+		return err
+	}
+	if err = node.verifyBlock(parent, block); err != nil {
+		return err
+	}
+	if node.light {
+		err = node.storage.SetHeader(block.Block)
+	} else {
+		err = node.storage.SetBlock(block.Block, block.Transactions)
+	}
+	if err != nil {
+		return err
+	}
+	if node.p2pServer != nil {
+		node.p2pServer.BroadcastBlock(block)
+	}
+	return nil
+}
+
+// verifyBlock checks that block extends parent, is signed by the validator and carries the transactions it lists
+func (node *Node) verifyBlock(parent *types.Block, block *storage.BlockWithTransactions) error {
+	validator, err := node.expectedValidator()
+	if err != nil {
+		return err
+	}
+	b := block.Block
+	if err = verifyHeader(parent, b, validator); err != nil {
+		return err
+	}
+
+	if len(block.Transactions) != len(b.Transactions) {
+		return fmt.Errorf("%w: %d transactions listed but %d sent", p2p.ErrInvalidBlock, len(b.Transactions), len(block.Transactions))
+	}
+	for i, tx := range block.Transactions {
+		if tx == nil || tx.Hash != b.Transactions[i] || tx.BlockHeight != b.Height {
+			return fmt.Errorf("%w: transaction %d doesn't match the block", p2p.ErrInvalidBlock, i)
+		}
+		if err = tx.Validate(); err != nil {
+			return fmt.Errorf("%w: %s", p2p.ErrInvalidBlock, err.Error())
+		}
+	}
+	return nil
+}
+
+// verifyHeader checks that b extends parent and is signed by validator
+func verifyHeader(parent *types.Block, b *types.Block, validator ecommon.Address) error {
+	switch {
+	case b.ChainId != common.DummyChainId:
+		return fmt.Errorf("%w: unexpected chain id %d", p2p.ErrInvalidBlock, b.ChainId)
+	case b.Height != parent.Height+1 || b.PrevHash != parent.Hash:
+		return fmt.Errorf("%w: block %d doesn't extend block %d", p2p.ErrInvalidBlock, b.Height, parent.Height)
+	case b.Hash != b.GetHash():
+		return fmt.Errorf("%w: hash mismatch", p2p.ErrInvalidBlock)
+	}
+
+	if b.Validator != validator {
+		return fmt.Errorf("%w: created by %s instead of %s", p2p.ErrInvalidBlock, b.Validator, validator)
+	}
+	if err := b.VerifySignature(); err != nil {
+		return fmt.Errorf("%w: %s", p2p.ErrInvalidBlock, err.Error())
+	}
+	return nil
+}
+
+// expectedValidator is the configured validator, or the one of block 1 served by the validator Url
+// Peers can't be trusted with it, any of them could feed a chain of its own, so without Url no block is accepted
+func (node *Node) expectedValidator() (ecommon.Address, error) {
+	if node.globalConfig.P2pConfig.ValidatorAddress != "" {
+		return ecommon.HexToAddress(node.globalConfig.P2pConfig.ValidatorAddress), nil
+	}
+
+	node.validatorLock.Lock()
+	defer node.validatorLock.Unlock()
+	if node.validator != nil {
+		return *node.validator, nil
+	}
+	first, err := node.rpcClient.GetBlockByHeight(context.Background(), 1)
+	if err != nil {
+		return ecommon.Address{}, fmt.Errorf("%w: the validator of block 1 is unknown, set P2p.ValidatorAddress or make Url reachable: %s", p2p.ErrUnverifiable, err.Error())
+	}
+	validator := ecommon.HexToAddress(first.Validator)
+
+	// A block 1 stored before would have come from the same validator
+	height, err := node.storage.GetHeight()
+	if err != nil {
+		return ecommon.Address{}, err
+	}
+	if height > 0 {
+		stored, errStored := node.storage.GetBlockByHeight(1)
+		if errStored != nil {
+			return ecommon.Address{}, errStored
+		}
+		if stored.Validator != validator {
+			return ecommon.Address{}, errors.Errorf("the stored chain was created by %s but Url serves the chain of %s", stored.Validator, validator)
+		}
+	}
+	node.validator = &validator
+	return validator, nil
+}
@@ -0,0 +1,536 @@
+package node
+
+import (
+	"context"
+	"dummy-chain/common"
+	"dummy-chain/common/config"
+	"dummy-chain/common/types"
+	"dummy-chain/p2p"
+	"dummy-chain/rpc"
+	"dummy-chain/storage"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Headers verified ahead of the bodies, the bodies of a segment are downloaded before the next headers
+	syncSegmentSize = 10 * common.MaxHeadersInterval
+	// Windows of bodies downloaded at once from every source
+	syncWorkersPerSource = 2
+	// Blocks downloaded but not applied yet, per worker, before the workers wait for the applier
+	syncAheadPerWorker = 2 * common.MaxBlocksInterval
+
+	// Body batches start at initialBodyBatch blocks, double when a window is fetched within fastBodyBatch
+	// and halve when it takes longer than slowBodyBatch or fails
+	initialBodyBatch = 10
+	fastBodyBatch    = 500 * time.Millisecond
+	slowBodyBatch    = 2 * time.Second
+	// A source failing this many windows in a row is given up for the current sync
+	maxSourceFailures = 5
+	// Wait of a rate limited source that didn't send Retry-After
+	rateLimitedWait = time.Second
+
+	syncProgressInterval = 10 * time.Second
+)
+
+var errNoSyncSource = errors.New("no source left to download blocks from")
+
+// syncSource downloads block bodies from one validator endpoint with its own adaptive batch size
+type syncSource struct {
+	url    string
+	client *rpc.Client
+	batch  atomic.Int64
+}
+
+// newSyncSources creates a source for Url and every fallback url, so bodies are downloaded from all of them at once
+func newSyncSources(baseConfig config.BaseConfig) ([]*syncSource, error) {
+	urls := append([]string{baseConfig.Url}, baseConfig.FallbackUrls...)
+	sources := make([]*syncSource, 0, len(urls))
+	for _, url := range urls {
+		// Failed windows go to the other sources rather than being retried on the same one
+		client, err := rpc.NewClient(url, rpc.WithAuthToken(baseConfig.Token), rpc.WithRetries(0))
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, &syncSource{url: url, client: client})
+	}
+	return sources, nil
+}
+
+// adapt grows the batch of a source answering quickly and shrinks it when it is slow or failed
+func (s *syncSource) adapt(elapsed time.Duration, failed bool) {
+	size := s.batch.Load()
+	switch {
+	case failed || elapsed > slowBodyBatch:
+		size /= 2
+	case elapsed < fastBodyBatch:
+		size *= 2
+	}
+	s.batch.Store(min(max(size, 1), common.MaxBlocksInterval))
+}
+
+// fetch downloads the bodies of headers and checks they are the blocks the headers describe
+func (s *syncSource) fetch(ctx context.Context, headers []*types.Block) ([]*storage.BlockWithTransactions, error) {
+	from, to := headers[0].Height, headers[len(headers)-1].Height
+	list, err := s.client.GetBlocksInterval(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Blocks) != len(headers) {
+		return nil, errors.Errorf("%d blocks returned for %d headers", len(list.Blocks), len(headers))
+	}
+
+	result := make([]*storage.BlockWithTransactions, 0, len(headers))
+	for i, blockInfo := range list.Blocks {
+		block, err := blockInfoToBlock(blockInfo)
+		if err != nil {
+			return nil, err
+		}
+		if block.Block.Hash != headers[i].Hash {
+			return nil, errors.Errorf("block %d doesn't match its header", headers[i].Height)
+		}
+		result = append(result, block)
+	}
+	return result, nil
+}
+
+// syncTracker keeps the progress of the running sync for SyncStatus and the progress log
+type syncTracker struct {
+	lock    sync.Mutex
+	status  types.SyncStatus
+	started time.Time
+}
+
+func (t *syncTracker) start(height, target uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.started = time.Now()
+	t.status = types.SyncStatus{
+		Syncing:        true,
+		Stage:          "headers",
+		StartingHeight: height,
+		CurrentHeight:  height,
+		HeadersHeight:  height,
+		TargetHeight:   target,
+	}
+}
+
+func (t *syncTracker) update(change func(status *types.SyncStatus)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	change(&t.status)
+}
+
+func (t *syncTracker) finish(height uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.status = types.SyncStatus{
+		StartingHeight: height,
+		CurrentHeight:  height,
+		HeadersHeight:  height,
+		TargetHeight:   height,
+	}
+}
+
+// get returns the status with the speed and the time left estimated from the blocks applied so far
+func (t *syncTracker) get() types.SyncStatus {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	status := t.status
+	if !status.Syncing {
+		return status
+	}
+	elapsed := time.Since(t.started).Seconds()
+	applied := status.CurrentHeight - status.StartingHeight
+	if elapsed > 0 && applied > 0 {
+		status.BlocksPerSecond = float64(applied) / elapsed
+		status.Eta = int64(float64(status.TargetHeight-status.CurrentHeight) / status.BlocksPerSecond)
+	}
+	return status
+}
+
+// SyncStatus reports the progress of the node catching up with the validator
+func (node *Node) SyncStatus() types.SyncStatus {
+	status := node.syncTracker.get()
+	if !status.Syncing {
+		if height, err := node.storage.GetHeight(); err == nil {
+			status.StartingHeight, status.CurrentHeight, status.HeadersHeight, status.TargetHeight = height, height, height, height
+		}
+	}
+	return status
+}
+
+// Sync catches up with the validator headers first: a segment of headers is downloaded and verified,
+// then the bodies are downloaded in parallel windows from every source and applied in order
+// A light node stores the verified headers and skips the bodies
+func (node *Node) Sync(ctx context.Context) error {
+	node.syncLock.Lock()
+	defer node.syncLock.Unlock()
+
+	height, err := node.storage.GetHeight()
+	if err != nil {
+		return err
+	}
+	target, err := node.rpcClient.GetCurrentBlockHeight(ctx)
+	if err != nil {
+		return err
+	} else if target <= height {
+		return nil
+	}
+
+	node.syncTracker.start(height, target)
+	defer func() {
+		current, _ := node.storage.GetHeight()
+		node.syncTracker.finish(current)
+	}()
+	node.logger.Debugf("Syncing from block %d to %d", height, target)
+
+	for height < target {
+		to := min(height+syncSegmentSize, target)
+		node.syncTracker.update(func(status *types.SyncStatus) { status.Stage = "headers" })
+		headers, err := node.fetchHeaders(ctx, height, to)
+		if err != nil {
+			return err
+		}
+		if node.light {
+			err = node.applyHeaders(headers)
+		} else {
+			node.syncTracker.update(func(status *types.SyncStatus) { status.Stage = "bodies" })
+			err = node.fetchBodies(ctx, headers)
+		}
+		if err != nil {
+			return err
+		}
+
+		if height, err = node.storage.GetHeight(); err != nil {
+			return err
+		}
+		if height >= target {
+			// Blocks created meanwhile are picked up as well
+			if target, err = node.rpcClient.GetCurrentBlockHeight(ctx); err != nil {
+				return err
+			}
+			node.syncTracker.update(func(status *types.SyncStatus) { status.TargetHeight = target })
+		}
+	}
+	node.logger.Debugf("Synced up to block %d", height)
+	return nil
+}
+
+// fetchHeaders downloads and verifies the headers after height up to to, they must extend the stored chain
+func (node *Node) fetchHeaders(ctx context.Context, height, to uint64) ([]*types.Block, error) {
+	parent, err := node.storage.GetBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	validator, err := node.expectedValidator()
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make([]*types.Block, 0, to-height)
+	for parent.Height < to {
+		right := min(parent.Height+common.MaxHeadersInterval, to)
+		list, err := node.rpcClient.GetHeadersInterval(ctx, parent.Height+1, right)
+		if err != nil {
+			return nil, err
+		} else if list.Count == 0 {
+			return nil, errors.Errorf("no header after block %d", parent.Height)
+		}
+		for _, headerInfo := range list.Headers {
+			header, err := headerInfo.ToBlock()
+			if err != nil {
+				return nil, err
+			}
+			if err = verifyHeader(parent, header, validator); err != nil {
+				return nil, err
+			}
+			headers = append(headers, header)
+			parent = header
+		}
+		node.syncTracker.update(func(status *types.SyncStatus) { status.HeadersHeight = parent.Height })
+	}
+	return headers, nil
+}
+
+// applyHeaders stores the verified headers on a light node, the ones a peer delivered meanwhile are skipped
+func (node *Node) applyHeaders(headers []*types.Block) error {
+	node.applyLock.Lock()
+	defer node.applyLock.Unlock()
+
+	for _, header := range headers {
+		height, err := node.storage.GetHeight()
+		if err != nil {
+			return err
+		}
+		if header.Height <= height {
+			if err = node.checkKnownBlock(header); !errors.Is(err, p2p.ErrKnownBlock) {
+				return err
+			}
+			continue
+		}
+		if err = node.storage.SetHeader(header); err != nil {
+			return errors.Wrapf(err, "failed to store header %d", header.Height)
+		}
+		node.syncTracker.update(func(status *types.SyncStatus) { status.CurrentHeight = header.Height })
+	}
+	return nil
+}
+
+// bodyDownload hands out windows of headers to the workers and collects the blocks for the applier
+type bodyDownload struct {
+	headers []*types.Block
+	first   uint64
+
+	lock    sync.Mutex
+	cond    *sync.Cond
+	next    uint64
+	applied uint64
+	retry   [][]*types.Block
+	// Windows handed out and neither delivered nor given back
+	inFlight int
+	results  map[uint64][]*storage.BlockWithTransactions
+	err      error
+	ahead    uint64
+}
+
+// take returns the next window of at most size headers, waiting while too many blocks wait to be applied
+// It returns nil once every window was delivered or the download failed
+func (d *bodyDownload) take(size int64) []*types.Block {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for {
+		if d.err != nil {
+			return nil
+		}
+		if len(d.retry) > 0 {
+			window := d.retry[len(d.retry)-1]
+			d.retry = d.retry[:len(d.retry)-1]
+			d.inFlight += 1
+			return window
+		}
+		end := d.first + uint64(len(d.headers))
+		if d.next >= end && d.inFlight == 0 {
+			return nil
+		}
+		if d.next < end && d.next-d.applied < d.ahead {
+			from := d.next - d.first
+			to := min(from+uint64(size), uint64(len(d.headers)))
+			d.next = d.first + to
+			d.inFlight += 1
+			return d.headers[from:to]
+		}
+		// Windows of other workers may still fail and come back
+		d.cond.Wait()
+	}
+}
+
+// giveBack queues a window that failed for another worker
+func (d *bodyDownload) giveBack(window []*types.Block) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.retry = append(d.retry, window)
+	d.inFlight -= 1
+	d.cond.Broadcast()
+}
+
+func (d *bodyDownload) deliver(blocks []*storage.BlockWithTransactions) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.results[blocks[0].Block.Height] = blocks
+	d.inFlight -= 1
+	d.cond.Broadcast()
+}
+
+func (d *bodyDownload) fail(err error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.err == nil {
+		d.err = err
+	}
+	d.cond.Broadcast()
+}
+
+// wait returns the blocks starting at height once they were downloaded
+func (d *bodyDownload) wait(height uint64) ([]*storage.BlockWithTransactions, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for {
+		if blocks, ok := d.results[height]; ok {
+			delete(d.results, height)
+			return blocks, nil
+		}
+		if d.err != nil {
+			return nil, d.err
+		}
+		d.cond.Wait()
+	}
+}
+
+func (d *bodyDownload) setApplied(height uint64) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.applied = height
+	d.cond.Broadcast()
+}
+
+// fetchBodies downloads the blocks of the verified headers from every source in parallel and applies them in order
+func (node *Node) fetchBodies(ctx context.Context, headers []*types.Block) error {
+	if len(headers) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := len(node.syncSources) * syncWorkersPerSource
+	d := &bodyDownload{
+		headers: headers,
+		first:   headers[0].Height,
+		next:    headers[0].Height,
+		applied: headers[0].Height,
+		results: make(map[uint64][]*storage.BlockWithTransactions),
+		ahead:   uint64(workers * syncAheadPerWorker),
+	}
+	d.cond = sync.NewCond(&d.lock)
+
+	var wg sync.WaitGroup
+	for _, source := range node.syncSources {
+		if source.batch.Load() == 0 {
+			source.batch.Store(initialBodyBatch)
+		}
+		for i := 0; i < syncWorkersPerSource; i++ {
+			wg.Add(1)
+			go func(source *syncSource) {
+				defer wg.Done()
+				node.downloadBodies(ctx, source, d)
+			}(source)
+		}
+	}
+	go func() {
+		// Every worker gave up before the last window was delivered
+		wg.Wait()
+		d.fail(errNoSyncSource)
+	}()
+	defer func() {
+		d.fail(context.Canceled)
+		cancel()
+		wg.Wait()
+	}()
+
+	lastReport := time.Now()
+	last := headers[len(headers)-1].Height
+	for height := d.first; height <= last; {
+		blocks, err := d.wait(height)
+		if err != nil {
+			return err
+		}
+		for _, block := range blocks {
+			if err = node.applyBlock(block); err != nil && !errors.Is(err, p2p.ErrKnownBlock) {
+				return errors.Wrapf(err, "failed to apply block %d", block.Block.Height)
+			}
+		}
+		height += uint64(len(blocks))
+		d.setApplied(height)
+		node.syncTracker.update(func(status *types.SyncStatus) { status.CurrentHeight = height - 1 })
+
+		if time.Since(lastReport) >= syncProgressInterval {
+			lastReport = time.Now()
+			node.logSyncProgress()
+		}
+	}
+	return nil
+}
+
+// downloadBodies fetches windows from source until none is left or the source failed too often
+func (node *Node) downloadBodies(ctx context.Context, source *syncSource, d *bodyDownload) {
+	failures := 0
+	for {
+		window := d.take(source.batch.Load())
+		if window == nil {
+			return
+		}
+		started := time.Now()
+		blocks, err := source.fetch(ctx, window)
+		if err != nil {
+			d.giveBack(window)
+			if ctx.Err() != nil {
+				return
+			}
+			if wait, limited := rateLimited(err); limited {
+				// The source is healthy but busy, its batch and failures are kept
+				node.logger.Debugf("Rate limited by %s, waiting %s", source.url, wait)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wait):
+				}
+				continue
+			}
+			source.adapt(0, true)
+			failures += 1
+			node.logger.Debugf("Failed to download blocks %d to %d from %s: %s",
+				window[0].Height, window[len(window)-1].Height, source.url, err.Error())
+			if failures >= maxSourceFailures {
+				return
+			}
+			// Leave the window to a healthier source for a while
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(failures) * slowBodyBatch):
+			}
+			continue
+		}
+		failures = 0
+		source.adapt(time.Since(started), false)
+		d.deliver(blocks)
+	}
+}
+
+// rateLimited tells whether err is a 429 reply and how long to wait before the next request
+func rateLimited(err error) (time.Duration, bool) {
+	var httpErr *rpc.HttpError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if httpErr.RetryAfter <= 0 {
+		return rateLimitedWait, true
+	}
+	return httpErr.RetryAfter, true
+}
+
+func (node *Node) logSyncProgress() {
+	status := node.syncTracker.get()
+	progress := 100.0
+	if status.TargetHeight > status.StartingHeight {
+		progress = float64(status.CurrentHeight-status.StartingHeight) * 100 / float64(status.TargetHeight-status.StartingHeight)
+	}
+	eta := "unknown"
+	if status.Eta > 0 {
+		eta = (time.Duration(status.Eta) * time.Second).String()
+	}
+	node.logger.Debugf("Syncing block %d of %d (%.1f%%), %.1f blocks/s, %s left",
+		status.CurrentHeight, status.TargetHeight, progress, status.BlocksPerSecond, eta)
+}
+
+// blockInfoToBlock decodes a block received from the validator
+func blockInfoToBlock(blockInfo types.BlockInfo) (*storage.BlockWithTransactions, error) {
+	block, err := blockInfo.ToBlock()
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]*types.Transaction, 0, len(blockInfo.Transactions))
+	for _, txInfo := range blockInfo.Transactions {
+		tx, err := txInfo.ToTransaction()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", common.ErrInvalidTransaction, err.Error())
+		}
+		txs = append(txs, tx)
+	}
+	return &storage.BlockWithTransactions{Block: block, Transactions: txs}, nil
+}
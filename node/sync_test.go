@@ -0,0 +1,119 @@
+package node
+
+import (
+	"context"
+	"dummy-chain/common"
+	"dummy-chain/common/types"
+	"dummy-chain/rpc"
+	"dummy-chain/storage"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+func testChain(n int) []*types.Block {
+	blocks := make([]*types.Block, 0, n)
+	prevHash := ecommon.Hash{}
+	for height := 1; height <= n; height++ {
+		block := &types.Block{
+			ChainId:   common.DummyChainId,
+			Height:    uint64(height),
+			Timestamp: int64(height),
+			PrevHash:  prevHash,
+		}
+		block.Hash = block.GetHash()
+		prevHash = block.Hash
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// testBodyServer answers chain.GetBlocksInterval from blocks after rateLimited replies of 429
+func testBodyServer(t *testing.T, blocks []*types.Block, rateLimited int64) *httptest.Server {
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= rateLimited {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		var request struct {
+			Id     uint64
+			Params []rpc.BlockInterval
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+			return
+		}
+		interval := request.Params[0]
+		list := types.BlockInfoList{}
+		for _, block := range blocks[interval.Left-1 : interval.Right] {
+			list.Blocks = append(list.Blocks, block.ToInfo())
+		}
+		list.Count = uint64(len(list.Blocks))
+		result, _ := json.Marshal(list)
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": request.Id, "result": json.RawMessage(result)})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDownloadBodiesRateLimited(t *testing.T) {
+	headers := testChain(8)
+	server := testBodyServer(t, headers, 1)
+	client, err := rpc.NewClient(server.URL, rpc.WithRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := &syncSource{url: server.URL, client: client}
+	source.batch.Store(8)
+
+	d := &bodyDownload{
+		headers: headers,
+		first:   1,
+		next:    1,
+		applied: 1,
+		results: make(map[uint64][]*storage.BlockWithTransactions),
+		ahead:   uint64(len(headers)),
+	}
+	d.cond = sync.NewCond(&d.lock)
+	node := &Node{logger: zap.NewNop().Sugar()}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		node.downloadBodies(context.Background(), source, d)
+	}()
+	blocks, err := d.wait(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != len(headers) {
+		t.Fatalf("expected %d blocks, got %d", len(headers), len(blocks))
+	}
+	d.setApplied(uint64(len(headers)) + 1)
+	<-done
+
+	// A 429 doesn't halve the batch, the fast reply after it doubles it
+	if batch := source.batch.Load(); batch != 16 {
+		t.Fatalf("expected a batch of 16, got %d", batch)
+	}
+}
+
+func TestRateLimited(t *testing.T) {
+	if _, limited := rateLimited(&rpc.HttpError{StatusCode: http.StatusServiceUnavailable}); limited {
+		t.Fatal("a 503 is a failure")
+	}
+	if wait, limited := rateLimited(&rpc.HttpError{StatusCode: http.StatusTooManyRequests}); !limited || wait != rateLimitedWait {
+		t.Fatalf("expected the default wait, got %s %v", wait, limited)
+	}
+	if wait, limited := rateLimited(&rpc.HttpError{StatusCode: http.StatusTooManyRequests, RetryAfter: 3 * time.Second}); !limited || wait != 3*time.Second {
+		t.Fatalf("expected the Retry-After wait, got %s %v", wait, limited)
+	}
+}
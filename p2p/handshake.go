@@ -0,0 +1,92 @@
+package p2p
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"dummy-chain/common"
+	"dummy-chain/common/types"
+	"net"
+	"time"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+const (
+	ProtocolVersion = 1
+
+	handshakeTimeout = 10 * time.Second
+	nonceSize        = 32
+)
+
+// Domain separator so a handshake signature can't be replayed as a transaction or block signature
+var handshakeDomain = []byte("dummy-chain p2p handshake")
+
+var (
+	ErrSelfConnection  = errors.New("connected to self")
+	ErrChainMismatch   = errors.New("peer is on another chain")
+	ErrVersionMismatch = errors.New("peer speaks another protocol version")
+)
+
+// handshakeHash is what a node signs to prove it owns address to the peer that sent nonce
+func handshakeHash(nonce []byte, address ecommon.Address) ecommon.Hash {
+	return crypto.Keccak256Hash(handshakeDomain, nonce, address.Bytes())
+}
+
+// handshake exchanges Hello messages and then signatures over the nonce of the other side
+// Both sides run the same steps, so it doesn't matter who dialed
+func handshake(conn net.Conn, key *ecdsa.PrivateKey, local Hello) (*Hello, error) {
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return nil, err
+	}
+	defer conn.SetDeadline(time.Time{})
+
+	local.Nonce = make([]byte, nonceSize)
+	if _, err := rand.Read(local.Nonce); err != nil {
+		return nil, err
+	}
+	if err := writeMessage(conn, &Message{Kind: HelloMessage, Hello: &local}); err != nil {
+		return nil, err
+	}
+	message, err := readMessageLimit(conn, maxHandshakeFrame)
+	if err != nil {
+		return nil, err
+	}
+	if message.Kind != HelloMessage || message.Hello == nil {
+		return nil, errors.New("expected hello")
+	}
+	remote := message.Hello
+	switch {
+	case remote.ChainId != common.DummyChainId:
+		return nil, ErrChainMismatch
+	case remote.Version != ProtocolVersion:
+		return nil, ErrVersionMismatch
+	case remote.Address == local.Address:
+		return nil, ErrSelfConnection
+	case len(remote.Nonce) != nonceSize:
+		return nil, errors.New("invalid hello nonce")
+	}
+
+	signature, err := crypto.Sign(handshakeHash(remote.Nonce, local.Address).Bytes(), key)
+	if err != nil {
+		return nil, err
+	}
+	if err = writeMessage(conn, &Message{Kind: AuthMessage, Signature: signature}); err != nil {
+		return nil, err
+	}
+	if message, err = readMessageLimit(conn, maxHandshakeFrame); err != nil {
+		return nil, err
+	}
+	if message.Kind != AuthMessage {
+		return nil, errors.New("expected auth")
+	}
+	signer, err := types.RecoverSigner(handshakeHash(local.Nonce, remote.Address), message.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if signer != remote.Address {
+		return nil, common.ErrInvalidSignature
+	}
+	return remote, nil
+}
@@ -0,0 +1,38 @@
+package p2p
+
+import ecommon "github.com/ethereum/go-ethereum/common"
+
+// knownSet remembers the last capacity hashes, the oldest is forgotten first
+type knownSet struct {
+	hashes map[ecommon.Hash]struct{}
+	order  []ecommon.Hash
+	next   int
+}
+
+func newKnownSet(capacity int) *knownSet {
+	return &knownSet{
+		hashes: make(map[ecommon.Hash]struct{}, capacity),
+		order:  make([]ecommon.Hash, 0, capacity),
+	}
+}
+
+// add returns false when hash was already known
+func (k *knownSet) add(hash ecommon.Hash) bool {
+	if _, ok := k.hashes[hash]; ok {
+		return false
+	}
+	if len(k.order) < cap(k.order) {
+		k.order = append(k.order, hash)
+	} else {
+		delete(k.hashes, k.order[k.next])
+		k.order[k.next] = hash
+		k.next = (k.next + 1) % len(k.order)
+	}
+	k.hashes[hash] = struct{}{}
+	return true
+}
+
+func (k *knownSet) contains(hash ecommon.Hash) bool {
+	_, ok := k.hashes[hash]
+	return ok
+}
@@ -0,0 +1,105 @@
+package p2p
+
+import (
+	"bytes"
+	"dummy-chain/common/types"
+	"dummy-chain/storage"
+	"encoding/binary"
+	"encoding/gob"
+	"io"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Largest frame accepted from a peer, a full page of blocks fits easily
+	maxFrameSize = 32 * 1024 * 1024
+	// Largest Hello or Auth frame, the peer isn't authenticated yet
+	maxHandshakeFrame = 4 * 1024
+)
+
+type MessageKind uint8
+
+const (
+	HelloMessage MessageKind = iota + 1
+	AuthMessage
+	TransactionsMessage
+	BlockMessage
+	GetBlocksMessage
+	BlocksMessage
+	GetPeersMessage
+	PeersMessage
+)
+
+// Hello opens the handshake, Nonce is signed back by the other side to prove it owns Address
+type Hello struct {
+	ChainId uint64
+	Version uint32
+	Address ecommon.Address
+	Nonce   []byte
+	Height  uint64
+	// Port the node accepts peers on, 0 when it only dials out
+	ListenPort uint16
+}
+
+// Message is the envelope of every frame, only the fields of its kind are set
+type Message struct {
+	Kind MessageKind
+
+	Hello     *Hello
+	Signature []byte
+
+	Transactions []*types.Transaction
+	Blocks       []*storage.BlockWithTransactions
+
+	// Inclusive range of a GetBlocksMessage
+	From uint64
+	To   uint64
+
+	// host:port of known nodes in a PeersMessage
+	Addresses []string
+}
+
+// writeMessage sends a frame made of the big endian size of the gob encoded message followed by the message
+func writeMessage(w io.Writer, message *Message) error {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 4))
+	if err := gob.NewEncoder(&buf).Encode(message); err != nil {
+		return err
+	}
+	frame := buf.Bytes()
+	size := len(frame) - 4
+	if size > maxFrameSize {
+		return errors.Errorf("message of %d bytes is too large", size)
+	}
+	binary.BigEndian.PutUint32(frame, uint32(size))
+	_, err := w.Write(frame)
+	return err
+}
+
+func readMessage(r io.Reader) (*Message, error) {
+	return readMessageLimit(r, maxFrameSize)
+}
+
+// readMessageLimit reads a frame of at most limit bytes, the size is checked before anything is allocated
+func readMessageLimit(r io.Reader, limit uint32) (*Message, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > limit {
+		return nil, errors.Errorf("message of %d bytes is too large", size)
+	}
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return nil, err
+	}
+
+	var message Message
+	if err := gob.NewDecoder(bytes.NewReader(frame)).Decode(&message); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
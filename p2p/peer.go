@@ -0,0 +1,183 @@
+package p2p
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// Messages queued for a peer before it is considered too slow and dropped
+	peerQueueSize = 256
+	writeTimeout  = 10 * time.Second
+
+	// Scores move between these bounds, a node at the lowest one is banned
+	maxScore = 100
+	banScore = -100
+)
+
+// PeerInfo describes a connected peer for admin.Peers
+type PeerInfo struct {
+	Address       ecommon.Address
+	RemoteAddress string
+	// Address to dial the node at, empty for an inbound peer that doesn't accept connections
+	DialAddress string
+	Inbound     bool
+	Static      bool
+	Height      uint64
+	Score       int64
+}
+
+// Peer is an authenticated connection to another node
+type Peer struct {
+	conn        net.Conn
+	address     ecommon.Address
+	dialAddress string
+	inbound     bool
+	static      bool
+	height      atomic.Uint64
+	score       atomic.Int64
+	syncing     atomic.Bool
+	queue       chan *Message
+	closed      chan struct{}
+	closeErr    error
+	once        sync.Once
+	// Closed to have the write loop flush the queue and exit, done once it did
+	draining  chan struct{}
+	drainOnce sync.Once
+	done      chan struct{}
+}
+
+func newPeer(conn net.Conn, hello *Hello, inbound bool, dialAddress string) *Peer {
+	p := &Peer{
+		conn:        conn,
+		address:     hello.Address,
+		dialAddress: dialAddress,
+		inbound:     inbound,
+		queue:       make(chan *Message, peerQueueSize),
+		closed:      make(chan struct{}),
+		draining:    make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	p.height.Store(hello.Height)
+	return p
+}
+
+func (p *Peer) Address() ecommon.Address {
+	return p.address
+}
+
+func (p *Peer) RemoteAddr() string {
+	return p.conn.RemoteAddr().String()
+}
+
+func (p *Peer) Inbound() bool {
+	return p.inbound
+}
+
+// Height is the highest block the peer is known to have
+func (p *Peer) Height() uint64 {
+	return p.height.Load()
+}
+
+// Score grows with the blocks the peer brings and drops when it misbehaves
+func (p *Peer) Score() int64 {
+	return p.score.Load()
+}
+
+// adjustScore adds delta to the score, kept between banScore and maxScore, and returns the new one
+func (p *Peer) adjustScore(delta int64) int64 {
+	for {
+		current := p.score.Load()
+		next := min(max(current+delta, banScore), maxScore)
+		if p.score.CompareAndSwap(current, next) {
+			return next
+		}
+	}
+}
+
+func (p *Peer) info() PeerInfo {
+	return PeerInfo{
+		Address:       p.address,
+		RemoteAddress: p.RemoteAddr(),
+		DialAddress:   p.dialAddress,
+		Inbound:       p.inbound,
+		Static:        p.static,
+		Height:        p.Height(),
+		Score:         p.Score(),
+	}
+}
+
+func (p *Peer) updateHeight(height uint64) {
+	for {
+		current := p.height.Load()
+		if height <= current || p.height.CompareAndSwap(current, height) {
+			return
+		}
+	}
+}
+
+// send queues a message without blocking, a peer that can't keep up is disconnected
+func (p *Peer) send(message *Message) {
+	select {
+	case p.queue <- message:
+	case <-p.closed:
+	default:
+		p.close(errSlowPeer)
+	}
+}
+
+func (p *Peer) close(err error) {
+	p.once.Do(func() {
+		p.closeErr = err
+		close(p.closed)
+		p.conn.Close()
+	})
+}
+
+// drain writes the queued messages before the peer is closed, waiting at most timeout
+func (p *Peer) drain(timeout time.Duration) {
+	p.drainOnce.Do(func() { close(p.draining) })
+	select {
+	case <-p.done:
+	case <-p.closed:
+	case <-time.After(timeout):
+	}
+}
+
+func (p *Peer) writeLoop() {
+	defer close(p.done)
+	for {
+		select {
+		case <-p.closed:
+			return
+		case <-p.draining:
+			for {
+				select {
+				case message := <-p.queue:
+					if err := p.write(message); err != nil {
+						p.close(err)
+						return
+					}
+				default:
+					return
+				}
+			}
+		case message := <-p.queue:
+			if err := p.write(message); err != nil {
+				p.close(err)
+				return
+			}
+		}
+	}
+}
+
+func (p *Peer) write(message *Message) error {
+	if err := p.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	return writeMessage(p.conn, message)
+}
@@ -0,0 +1,282 @@
+package p2p
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// Discovered peers are forgotten after this many dials failed in a row
+	maxDialFailures = 10
+	// Most addresses sent in a PeersMessage and taken from one
+	maxPeerAddresses = 64
+	// Most addresses remembered, new ones are ignored past it
+	maxStoredPeers = 1024
+)
+
+// PeerRecord is what the peer store remembers about a dialable node
+type PeerRecord struct {
+	// Node address, zero until the first connection
+	Address ecommon.Address
+	Score   int64
+	// Added by admin.AddPeer, dialed like the configured static peers and never forgotten
+	Static   bool
+	LastSeen time.Time
+	Failures int
+}
+
+// peerStore is the peer database of the data dir, a JSON file rewritten when it changed
+type peerStore struct {
+	path string
+
+	lock   sync.Mutex
+	dirty  bool
+	Peers  map[string]*PeerRecord
+	Banned map[ecommon.Address]time.Time
+	// Remote IPs of the banned nodes, a node picks its own address but not its IP
+	BannedIps map[string]time.Time
+}
+
+// loadPeerStore reads the store at path, a missing file is an empty store
+func loadPeerStore(path string) (*peerStore, error) {
+	store := &peerStore{
+		path:      path,
+		Peers:     make(map[string]*PeerRecord),
+		Banned:    make(map[ecommon.Address]time.Time),
+		BannedIps: make(map[string]time.Time),
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, store); err != nil {
+		return nil, err
+	}
+	if store.Peers == nil {
+		store.Peers = make(map[string]*PeerRecord)
+	}
+	if store.Banned == nil {
+		store.Banned = make(map[ecommon.Address]time.Time)
+	}
+	if store.BannedIps == nil {
+		store.BannedIps = make(map[string]time.Time)
+	}
+	return store, nil
+}
+
+// save writes the store when it changed, through a temporary file so a crash doesn't leave half of it
+func (s *peerStore) save() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	data, err := json.MarshalIndent(s, "", "    ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// add records a dialable address and tells whether it was new
+func (s *peerStore) add(address string, static bool) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	record, exists := s.Peers[address]
+	if !exists {
+		if len(s.Peers) >= maxStoredPeers && !static {
+			return false
+		}
+		record = &PeerRecord{}
+		s.Peers[address] = record
+	}
+	if static && !record.Static {
+		record.Static = true
+	} else if exists {
+		return false
+	}
+	s.dirty = true
+	return !exists
+}
+
+func (s *peerStore) remove(address string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, exists := s.Peers[address]; exists {
+		delete(s.Peers, address)
+		s.dirty = true
+	}
+}
+
+// record returns a copy of what is known about address
+func (s *peerStore) record(address string) (PeerRecord, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	record, exists := s.Peers[address]
+	if !exists {
+		return PeerRecord{}, false
+	}
+	return *record, true
+}
+
+// connected records a successful connection to node at address
+func (s *peerStore) connected(address string, node ecommon.Address) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	record, exists := s.Peers[address]
+	if !exists {
+		record = &PeerRecord{}
+		s.Peers[address] = record
+	}
+	record.Address = node
+	record.LastSeen = time.Now()
+	record.Failures = 0
+	s.dirty = true
+}
+
+// disconnected keeps the score of the session for the next connection
+func (s *peerStore) disconnected(address string, score int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if record, exists := s.Peers[address]; exists {
+		record.Score = score
+		record.LastSeen = time.Now()
+		s.dirty = true
+	}
+}
+
+// dialFailed counts a failed dial, discovered peers failing too often are forgotten
+func (s *peerStore) dialFailed(address string, keep bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	record, exists := s.Peers[address]
+	if !exists {
+		return
+	}
+	record.Failures += 1
+	if record.Failures >= maxDialFailures && !keep && !record.Static {
+		delete(s.Peers, address)
+	}
+	s.dirty = true
+}
+
+// ban refuses node, and any node connecting from ip, until the given time
+func (s *peerStore) ban(node ecommon.Address, ip string, until time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.Banned[node] = until
+	if ip != "" {
+		s.BannedIps[ip] = until
+	}
+	s.dirty = true
+}
+
+func (s *peerStore) isBanned(node ecommon.Address) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.isBannedLocked(node)
+}
+
+func (s *peerStore) isBannedLocked(node ecommon.Address) bool {
+	return activeBan(s, s.Banned, node)
+}
+
+func (s *peerStore) isIpBanned(ip string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return activeBan(s, s.BannedIps, ip)
+}
+
+// statics returns the addresses added by admin.AddPeer
+func (s *peerStore) statics() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var result []string
+	for address, record := range s.Peers {
+		if record.Static {
+			result = append(result, address)
+		}
+	}
+	return result
+}
+
+// candidates returns at most n addresses to dial, the best scored and most reliable first
+// Banned nodes and the addresses skip returns true for are left out
+func (s *peerStore) candidates(n int, skip func(address string) bool) []string {
+	if n <= 0 {
+		return nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var result []string
+	for address, record := range s.Peers {
+		if skip(address) || s.isBannedLocked(record.Address) || activeBan(s, s.BannedIps, dialHost(address)) {
+			continue
+		}
+		result = append(result, address)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := s.Peers[result[i]], s.Peers[result[j]]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Failures < b.Failures
+	})
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+// shareable returns the addresses worth telling other nodes about, the ones that were reached and aren't banned
+func (s *peerStore) shareable() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var result []string
+	for address, record := range s.Peers {
+		if record.LastSeen.IsZero() || record.Failures > 0 || s.isBannedLocked(record.Address) || activeBan(s, s.BannedIps, dialHost(address)) {
+			continue
+		}
+		result = append(result, address)
+		if len(result) == maxPeerAddresses {
+			break
+		}
+	}
+	return result
+}
+
+// activeBan tells whether key is banned in bans, dropping the ban once it expired, the lock must be held
+func activeBan[K comparable](s *peerStore, bans map[K]time.Time, key K) bool {
+	until, banned := bans[key]
+	if banned && time.Now().After(until) {
+		delete(bans, key)
+		s.dirty = true
+		return false
+	}
+	return banned
+}
+
+// dialHost returns the host of a host:port address, or address itself when it has no port
+func dialHost(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return host
+}
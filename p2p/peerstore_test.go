@@ -0,0 +1,58 @@
+package p2p
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+)
+
+func TestPeerStoreBan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "peers.json")
+	store, err := loadPeerStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := ecommon.Address{1}
+	store.add("10.0.0.1:7000", false)
+	store.add("10.0.0.2:7000", false)
+	store.ban(node, "10.0.0.1", time.Now().Add(time.Hour))
+	store.ban(ecommon.Address{2}, "10.0.0.3", time.Now().Add(-time.Second))
+
+	if !store.isBanned(node) || !store.isIpBanned("10.0.0.1") {
+		t.Fatal("the node and its IP must be banned")
+	}
+	if store.isIpBanned("10.0.0.3") {
+		t.Fatal("an expired ban must be lifted")
+	}
+	// Another address announced from the banned IP is still refused
+	candidates := store.candidates(10, func(string) bool { return false })
+	if len(candidates) != 1 || candidates[0] != "10.0.0.2:7000" {
+		t.Fatalf("the banned IP must not be dialed, got %v", candidates)
+	}
+
+	if err = store.save(); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := loadPeerStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reloaded.isBanned(node) || !reloaded.isIpBanned("10.0.0.1") {
+		t.Fatal("the bans must survive a restart")
+	}
+}
+
+func TestDialHost(t *testing.T) {
+	tests := map[string]string{
+		"10.0.0.1:7000": "10.0.0.1",
+		"[::1]:7000":    "::1",
+		"node.example":  "node.example",
+	}
+	for address, expected := range tests {
+		if host := dialHost(address); host != expected {
+			t.Errorf("%q: expected %q, got %q", address, expected, host)
+		}
+	}
+}
@@ -0,0 +1,667 @@
+package p2p
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"dummy-chain/common"
+	"dummy-chain/common/config"
+	"dummy-chain/common/types"
+	"dummy-chain/storage"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+const (
+	// How often the static peers that are not connected are dialed again
+	dialInterval = 5 * time.Second
+	dialTimeout  = 5 * time.Second
+	// How long the messages still queued for the peers may take to be written on stop
+	drainTimeout = 2 * time.Second
+
+	// A node sending an invalid block is refused for banDuration, other protocol violations cost protocolPenalty
+	banDuration     = 24 * time.Hour
+	protocolPenalty = 25
+	// Earned for every block a peer brings first
+	blockReward = 1
+	// Hashes of the transactions and blocks already handled, so gossip doesn't loop
+	knownCacheSize = 16384
+	// Inbound connections still in the handshake, more are closed right away
+	maxPendingHandshakes = 32
+)
+
+var (
+	// ErrKnownBlock is returned by a Backend for a block it already has, it is neither relayed nor a fault
+	ErrKnownBlock = errors.New("known block")
+	// ErrMissingParent is returned by a Backend for a block above its next height, the blocks in between are requested
+	ErrMissingParent = errors.New("missing parent block")
+	// ErrInvalidBlock is returned by a Backend for a block that breaks the chain rules, its sender is banned
+	ErrInvalidBlock = errors.New("invalid block")
+	// ErrUnverifiable is returned by a Backend that can't check blocks yet, they are dropped without blaming the sender
+	ErrUnverifiable = errors.New("blocks can't be verified yet")
+
+	ErrBanned       = errors.New("peer is banned")
+	ErrTooManyPeers = errors.New("too many peers")
+
+	errSlowPeer = errors.New("peer is too slow")
+	errProtocol = errors.New("protocol violation")
+)
+
+// Backend is the chain the server gossips for, implemented by the node
+type Backend interface {
+	Height() (uint64, error)
+	// GetBlocks returns the stored blocks between from and to inclusive
+	GetBlocks(from, to uint64) ([]*storage.BlockWithTransactions, error)
+	// HandleTransaction checks a transaction received from a peer, it is relayed when nil is returned
+	HandleTransaction(tx *types.Transaction) error
+	// HandleBlock checks and applies a block received from a peer, it is relayed when nil is returned
+	HandleBlock(block *storage.BlockWithTransactions) error
+}
+
+// Server accepts and dials peers and gossips transactions and blocks between them
+// Peers are authenticated with the node keys, a node is identified by its address
+// Static peers are kept connected, other peers are learnt from the bootnodes and the peers and dialed up to MaxPeers
+type Server struct {
+	key     *ecdsa.PrivateKey
+	address ecommon.Address
+	config  config.P2pConfig
+	backend Backend
+	store   *peerStore
+
+	lock       sync.RWMutex
+	peers      map[ecommon.Address]*Peer
+	static     map[string]bool
+	bootnodes  map[string]bool
+	dialing    map[string]bool
+	listener   net.Listener
+	listenPort uint16
+
+	knownLock sync.Mutex
+	known     *knownSet
+
+	// Holds a slot for every inbound connection in the handshake
+	handshakes chan struct{}
+
+	dialNow chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewServer creates a server keeping its peer database at peerStorePath
+func NewServer(key *ecdsa.PrivateKey, p2pConfig config.P2pConfig, peerStorePath string, backend Backend) (*Server, error) {
+	store, err := loadPeerStore(peerStorePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the peer store")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		key:        key,
+		address:    crypto.PubkeyToAddress(key.PublicKey),
+		config:     p2pConfig,
+		backend:    backend,
+		store:      store,
+		peers:      make(map[ecommon.Address]*Peer),
+		static:     make(map[string]bool),
+		bootnodes:  make(map[string]bool),
+		dialing:    make(map[string]bool),
+		known:      newKnownSet(knownCacheSize),
+		handshakes: make(chan struct{}, maxPendingHandshakes),
+		dialNow:    make(chan struct{}, 1),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	for _, address := range p2pConfig.Peers {
+		s.static[address] = true
+		store.add(address, false)
+	}
+	for _, address := range store.statics() {
+		s.static[address] = true
+	}
+	for _, address := range p2pConfig.Bootnodes {
+		s.bootnodes[address] = true
+		store.add(address, false)
+	}
+	return s, nil
+}
+
+// Start listens on the configured address, if any, and keeps dialing peers
+func (s *Server) Start() error {
+	if s.config.ListenAddress != "" {
+		listener, err := net.Listen("tcp", s.config.ListenAddress)
+		if err != nil {
+			return err
+		}
+		s.listener = listener
+		s.listenPort = uint16(listener.Addr().(*net.TCPAddr).Port)
+		s.wg.Add(1)
+		go s.acceptLoop()
+	}
+	s.wg.Add(1)
+	go s.dialLoop()
+	return nil
+}
+
+// Stop flushes the queued messages, disconnects every peer and waits for the server goroutines
+func (s *Server) Stop() {
+	s.cancel()
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	peers := s.connectedPeers()
+	var drained sync.WaitGroup
+	for _, p := range peers {
+		drained.Add(1)
+		go func(p *Peer) {
+			defer drained.Done()
+			p.drain(drainTimeout)
+		}(p)
+	}
+	drained.Wait()
+	for _, p := range peers {
+		p.close(errors.New("server stopped"))
+	}
+	s.wg.Wait()
+	if err := s.store.save(); err != nil {
+		common.GlobalLogger.Debugf("p2p failed to save the peer store: %s", err.Error())
+	}
+}
+
+// WaitForPeers returns once a peer is connected or after timeout, it tells whether there is one
+func (s *Server) WaitForPeers(timeout time.Duration) bool {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for len(s.connectedPeers()) == 0 {
+		select {
+		case <-deadline.C:
+			return false
+		case <-s.ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
+
+// Peers describes the connected peers
+func (s *Server) Peers() []PeerInfo {
+	peers := s.connectedPeers()
+	result := make([]PeerInfo, 0, len(peers))
+	for _, p := range peers {
+		result = append(result, p.info())
+	}
+	return result
+}
+
+// AddPeer dials address now and keeps it connected as a static peer, across restarts too
+func (s *Server) AddPeer(address string) error {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return err
+	}
+	s.lock.Lock()
+	s.static[address] = true
+	s.lock.Unlock()
+	s.store.add(address, true)
+	s.wakeDialer()
+	return nil
+}
+
+func (s *Server) connectedPeers() []*Peer {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	result := make([]*Peer, 0, len(s.peers))
+	for _, p := range s.peers {
+		result = append(result, p)
+	}
+	return result
+}
+
+// BroadcastTransaction sends a local transaction to every peer and returns how many were reached
+func (s *Server) BroadcastTransaction(tx *types.Transaction) int {
+	s.markKnown(tx.Hash)
+	return s.broadcast(&Message{Kind: TransactionsMessage, Transactions: []*types.Transaction{tx}}, nil)
+}
+
+// BroadcastBlock sends a block created or applied locally to every peer
+func (s *Server) BroadcastBlock(block *storage.BlockWithTransactions) int {
+	s.markKnown(block.Block.Hash)
+	return s.broadcast(&Message{Kind: BlockMessage, Blocks: []*storage.BlockWithTransactions{block}}, nil)
+}
+
+func (s *Server) broadcast(message *Message, except *Peer) int {
+	count := 0
+	for _, p := range s.connectedPeers() {
+		if p != except {
+			p.send(message)
+			count += 1
+		}
+	}
+	return count
+}
+
+// markKnown records hash and tells whether it was new
+// The hash of a peer message is only recorded once the backend accepted it,
+// a forged body sent under a real hash must not hide the genuine one
+func (s *Server) markKnown(hash ecommon.Hash) bool {
+	s.knownLock.Lock()
+	defer s.knownLock.Unlock()
+	return s.known.add(hash)
+}
+
+func (s *Server) isKnown(hash ecommon.Hash) bool {
+	s.knownLock.Lock()
+	defer s.knownLock.Unlock()
+	return s.known.contains(hash)
+}
+
+func (s *Server) acceptLoop() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if s.ctx.Err() == nil {
+				common.GlobalLogger.Debugf("p2p accept failed: %s", err.Error())
+			}
+			return
+		}
+		if s.store.isIpBanned(dialHost(conn.RemoteAddr().String())) {
+			common.GlobalLogger.Debugf("p2p inbound peer %s rejected: %s", conn.RemoteAddr(), ErrBanned.Error())
+			conn.Close()
+			continue
+		}
+		select {
+		case s.handshakes <- struct{}{}:
+		default:
+			common.GlobalLogger.Debugf("p2p inbound peer %s rejected: too many pending handshakes", conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			if errSetup := s.setupPeer(conn, ""); errSetup != nil {
+				common.GlobalLogger.Debugf("p2p inbound peer %s rejected: %s", conn.RemoteAddr(), errSetup.Error())
+			}
+		}()
+	}
+}
+
+func (s *Server) dialLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(dialInterval)
+	defer ticker.Stop()
+
+	for {
+		s.dialPeers()
+		if err := s.store.save(); err != nil {
+			common.GlobalLogger.Debugf("p2p failed to save the peer store: %s", err.Error())
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.dialNow:
+		}
+	}
+}
+
+// wakeDialer has the dial loop run now rather than at the next tick
+func (s *Server) wakeDialer() {
+	select {
+	case s.dialNow <- struct{}{}:
+	default:
+	}
+}
+
+// dialPeers dials the static peers that are not connected, then the best known nodes until MaxPeers is reached
+func (s *Server) dialPeers() {
+	s.lock.Lock()
+	connected := make(map[string]bool)
+	dynamic := 0
+	for _, p := range s.peers {
+		if p.dialAddress != "" {
+			connected[p.dialAddress] = true
+		}
+		if !p.static {
+			dynamic += 1
+		}
+	}
+	for address := range s.dialing {
+		if !s.static[address] {
+			dynamic += 1
+		}
+	}
+
+	var targets []string
+	for address := range s.static {
+		if connected[address] || s.dialing[address] {
+			continue
+		}
+		if record, known := s.store.record(address); known && s.store.isBanned(record.Address) {
+			continue
+		}
+		if s.store.isIpBanned(dialHost(address)) {
+			continue
+		}
+		targets = append(targets, address)
+	}
+	targets = append(targets, s.store.candidates(s.config.MaxPeers-dynamic, func(address string) bool {
+		return connected[address] || s.dialing[address] || s.static[address]
+	})...)
+	for _, address := range targets {
+		s.dialing[address] = true
+	}
+	s.lock.Unlock()
+
+	for _, address := range targets {
+		s.wg.Add(1)
+		go func(address string) {
+			defer s.wg.Done()
+			if err := s.dial(address); err != nil {
+				common.GlobalLogger.Debugf("p2p peer %s: %s", address, err.Error())
+			}
+			s.lock.Lock()
+			delete(s.dialing, address)
+			s.lock.Unlock()
+		}(address)
+	}
+}
+
+// dial connects to address and serves the peer until it disconnects
+func (s *Server) dial(address string) error {
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(s.ctx, "tcp", address)
+	if err != nil {
+		if s.ctx.Err() == nil {
+			s.lock.RLock()
+			keep := s.static[address] || s.bootnodes[address]
+			s.lock.RUnlock()
+			s.store.dialFailed(address, keep)
+		}
+		return err
+	}
+	if s.store.isIpBanned(dialHost(conn.RemoteAddr().String())) {
+		// The address resolved to a banned IP
+		conn.Close()
+		return ErrBanned
+	}
+	err = s.setupPeer(conn, address)
+	if errors.Is(err, ErrSelfConnection) {
+		// Our own address, learnt from a peer
+		s.store.remove(address)
+	}
+	return err
+}
+
+// setupPeer authenticates conn and serves it until it disconnects
+// dialAddress is the address conn was dialed at, empty for an inbound connection
+func (s *Server) setupPeer(conn net.Conn, dialAddress string) error {
+	inbound := dialAddress == ""
+	height, err := s.backend.Height()
+	if err != nil {
+		if inbound {
+			<-s.handshakes
+		}
+		conn.Close()
+		return err
+	}
+	hello, err := handshake(conn, s.key, Hello{
+		ChainId:    common.DummyChainId,
+		Version:    ProtocolVersion,
+		Address:    s.address,
+		Height:     height,
+		ListenPort: s.listenPort,
+	})
+	if inbound {
+		<-s.handshakes
+	}
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if s.store.isBanned(hello.Address) {
+		conn.Close()
+		return ErrBanned
+	}
+	if inbound && hello.ListenPort != 0 {
+		// The node accepts connections too, on the port it announced
+		if host, _, errSplit := net.SplitHostPort(conn.RemoteAddr().String()); errSplit == nil {
+			dialAddress = net.JoinHostPort(host, strconv.Itoa(int(hello.ListenPort)))
+		}
+	}
+
+	p := newPeer(conn, hello, inbound, dialAddress)
+	if record, known := s.store.record(dialAddress); known {
+		p.score.Store(record.Score)
+	}
+	s.lock.Lock()
+	p.static = s.static[dialAddress]
+	if err = s.admit(p); err != nil {
+		s.lock.Unlock()
+		conn.Close()
+		return err
+	}
+	s.peers[p.address] = p
+	s.lock.Unlock()
+	if dialAddress != "" {
+		s.store.connected(dialAddress, p.address)
+	}
+	common.GlobalLogger.Debugf("p2p peer %s connected from %s at height %d", p.address, p.RemoteAddr(), p.Height())
+
+	go p.writeLoop()
+	p.send(&Message{Kind: GetPeersMessage})
+	s.requestBlocks(p, height)
+	err = s.readLoop(p)
+	if errors.Is(err, ErrInvalidBlock) {
+		s.ban(p, err)
+	} else if errors.Is(err, errProtocol) {
+		s.penalize(p, protocolPenalty, err)
+	}
+	p.close(err)
+
+	s.lock.Lock()
+	if s.peers[p.address] == p {
+		delete(s.peers, p.address)
+	}
+	s.lock.Unlock()
+	if dialAddress != "" {
+		s.store.disconnected(dialAddress, p.Score())
+	}
+	common.GlobalLogger.Debugf("p2p peer %s disconnected: %s", p.address, p.closeErr)
+	return nil
+}
+
+// admit checks that p may be added to the peers, the lock must be held
+func (s *Server) admit(p *Peer) error {
+	if s.ctx.Err() != nil {
+		return errors.New("server stopped")
+	}
+	if _, exists := s.peers[p.address]; exists {
+		return errors.Errorf("already connected to %s", p.address)
+	}
+	if p.static {
+		return nil
+	}
+	dynamic := 0
+	for _, other := range s.peers {
+		if !other.static {
+			dynamic += 1
+		}
+	}
+	if dynamic >= s.config.MaxPeers {
+		return ErrTooManyPeers
+	}
+	return nil
+}
+
+// penalize lowers the score of p, banning it when it reaches the lowest score
+func (s *Server) penalize(p *Peer, penalty int64, reason error) {
+	if p.adjustScore(-penalty) <= banScore {
+		s.ban(p, reason)
+	}
+}
+
+func (s *Server) ban(p *Peer, reason error) {
+	p.score.Store(banScore)
+	s.store.ban(p.address, dialHost(p.RemoteAddr()), time.Now().Add(banDuration))
+	common.GlobalLogger.Debugf("p2p peer %s banned: %s", p.address, reason.Error())
+}
+
+func (s *Server) readLoop(p *Peer) error {
+	for {
+		message, err := readMessage(p.conn)
+		if err != nil {
+			return err
+		}
+		if err = s.handle(p, message); err != nil {
+			return err
+		}
+	}
+}
+
+// handle processes a message of p, an error disconnects the peer
+func (s *Server) handle(p *Peer, message *Message) error {
+	switch message.Kind {
+	case TransactionsMessage:
+		for _, tx := range message.Transactions {
+			if tx == nil || s.isKnown(tx.Hash) {
+				continue
+			}
+			if err := s.backend.HandleTransaction(tx); err != nil {
+				common.GlobalLogger.Debugf("p2p transaction %s from %s rejected: %s", tx.Hash, p.address, err.Error())
+				if errors.Is(err, common.ErrInvalidTransaction) {
+					// A full mempool is nobody's fault, a forged or malformed transaction is the sender's
+					s.penalize(p, protocolPenalty, err)
+					if p.Score() <= banScore {
+						return ErrBanned
+					}
+				}
+				continue
+			}
+			if s.markKnown(tx.Hash) {
+				s.broadcast(&Message{Kind: TransactionsMessage, Transactions: []*types.Transaction{tx}}, p)
+			}
+		}
+	case BlockMessage:
+		for _, block := range message.Blocks {
+			if block == nil || block.Block == nil {
+				return fmt.Errorf("%w: empty block", errProtocol)
+			}
+			p.updateHeight(block.Block.Height)
+			if s.isKnown(block.Block.Hash) {
+				continue
+			}
+			if err := s.applyBlock(p, block); err != nil {
+				return err
+			}
+		}
+	case GetBlocksMessage:
+		if message.To < message.From {
+			return fmt.Errorf("%w: invalid block range", errProtocol)
+		}
+		to := message.To
+		if to-message.From >= common.MaxBlocksInterval {
+			to = message.From + common.MaxBlocksInterval - 1
+		}
+		blocks, err := s.backend.GetBlocks(message.From, to)
+		if err != nil {
+			return err
+		}
+		p.send(&Message{Kind: BlocksMessage, Blocks: blocks, From: message.From, To: to})
+	case BlocksMessage:
+		p.syncing.Store(false)
+		if len(message.Blocks) == 0 {
+			// Light nodes don't serve blocks, asking again would loop
+			return nil
+		}
+		for _, block := range message.Blocks {
+			if block == nil || block.Block == nil {
+				return fmt.Errorf("%w: empty block", errProtocol)
+			}
+			p.updateHeight(block.Block.Height)
+			err := s.backend.HandleBlock(block)
+			if errors.Is(err, ErrMissingParent) {
+				// The chain moved on through another peer in between, the next request starts over from the new height
+				break
+			} else if err == nil {
+				p.adjustScore(blockReward)
+			} else if errors.Is(err, ErrUnverifiable) {
+				common.GlobalLogger.Debugf("p2p blocks from %s dropped: %s", p.address, err.Error())
+				return nil
+			} else if !errors.Is(err, ErrKnownBlock) {
+				return errors.Wrapf(err, "block %d", block.Block.Height)
+			}
+			s.markKnown(block.Block.Hash)
+		}
+		if height, err := s.backend.Height(); err == nil {
+			s.requestBlocks(p, height)
+		}
+	case GetPeersMessage:
+		var addresses []string
+		for _, address := range s.store.shareable() {
+			if address != p.dialAddress {
+				addresses = append(addresses, address)
+			}
+		}
+		p.send(&Message{Kind: PeersMessage, Addresses: addresses})
+	case PeersMessage:
+		if len(message.Addresses) > maxPeerAddresses {
+			return fmt.Errorf("%w: %d peer addresses", errProtocol, len(message.Addresses))
+		}
+		learnt := false
+		for _, address := range message.Addresses {
+			if _, _, err := net.SplitHostPort(address); err == nil && s.store.add(address, false) {
+				learnt = true
+			}
+		}
+		if learnt {
+			s.wakeDialer()
+		}
+	default:
+		return fmt.Errorf("%w: unexpected message kind %d", errProtocol, message.Kind)
+	}
+	return nil
+}
+
+// applyBlock handles a gossiped block, relaying it when it was applied and catching up when its parent is missing
+func (s *Server) applyBlock(p *Peer, block *storage.BlockWithTransactions) error {
+	err := s.backend.HandleBlock(block)
+	switch {
+	case err == nil:
+		p.adjustScore(blockReward)
+		if s.markKnown(block.Block.Hash) {
+			s.broadcast(&Message{Kind: BlockMessage, Blocks: []*storage.BlockWithTransactions{block}}, p)
+		}
+		return nil
+	case errors.Is(err, ErrKnownBlock):
+		s.markKnown(block.Block.Hash)
+		return nil
+	case errors.Is(err, ErrUnverifiable):
+		common.GlobalLogger.Debugf("p2p block %d from %s dropped: %s", block.Block.Height, p.address, err.Error())
+		return nil
+	case errors.Is(err, ErrMissingParent):
+		// It is handled again once the blocks before it arrived
+		if height, errHeight := s.backend.Height(); errHeight == nil {
+			s.requestBlocks(p, height)
+		}
+		return nil
+	}
+	return errors.Wrapf(err, "block %d", block.Block.Height)
+}
+
+// requestBlocks asks p for the next page of blocks when it is ahead of height and no request is running
+func (s *Server) requestBlocks(p *Peer, height uint64) {
+	if p.Height() <= height || !p.syncing.CompareAndSwap(false, true) {
+		return
+	}
+	p.send(&Message{Kind: GetBlocksMessage, From: height + 1, To: height + common.MaxBlocksInterval})
+}
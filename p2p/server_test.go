@@ -0,0 +1,79 @@
+package p2p
+
+import (
+	"dummy-chain/common"
+	"dummy-chain/common/types"
+	"dummy-chain/storage"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+)
+
+// testBackend rejects every transaction with err
+type testBackend struct {
+	err error
+}
+
+func (b *testBackend) Height() (uint64, error) { return 0, nil }
+
+func (b *testBackend) GetBlocks(from, to uint64) ([]*storage.BlockWithTransactions, error) {
+	return nil, nil
+}
+
+func (b *testBackend) HandleTransaction(tx *types.Transaction) error { return b.err }
+
+func (b *testBackend) HandleBlock(block *storage.BlockWithTransactions) error { return nil }
+
+func newTestServer(t *testing.T, backend Backend) (*Server, *Peer) {
+	t.Helper()
+	store, err := loadPeerStore(filepath.Join(t.TempDir(), "peers.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, remote := net.Pipe()
+	t.Cleanup(func() {
+		local.Close()
+		remote.Close()
+	})
+	s := &Server{backend: backend, store: store, known: newKnownSet(16)}
+	return s, newPeer(local, &Hello{Address: ecommon.Address{1}}, true, "")
+}
+
+func TestInvalidTransactionsBanPeer(t *testing.T) {
+	s, p := newTestServer(t, &testBackend{err: fmt.Errorf("%w: hash mismatch", common.ErrInvalidTransaction)})
+
+	var err error
+	sent := 0
+	for err == nil && sent < 10 {
+		tx := &types.Transaction{Hash: ecommon.Hash{byte(sent)}}
+		err = s.handle(p, &Message{Kind: TransactionsMessage, Transactions: []*types.Transaction{tx}})
+		sent += 1
+	}
+	if !errors.Is(err, ErrBanned) {
+		t.Fatalf("a peer sending invalid transactions must be dropped, got %v", err)
+	}
+	if expected := int(-banScore / protocolPenalty); sent != expected {
+		t.Fatalf("expected the peer to be banned after %d transactions, got %d", expected, sent)
+	}
+	if !s.store.isBanned(p.address) {
+		t.Fatal("the peer must be banned")
+	}
+}
+
+func TestRefusedTransactionsKeepPeer(t *testing.T) {
+	s, p := newTestServer(t, &testBackend{err: errors.New("memory pool is full")})
+
+	for i := 0; i < 10; i++ {
+		tx := &types.Transaction{Hash: ecommon.Hash{byte(i)}}
+		if err := s.handle(p, &Message{Kind: TransactionsMessage, Transactions: []*types.Transaction{tx}}); err != nil {
+			t.Fatalf("a transaction refused for lack of room must not drop the peer: %v", err)
+		}
+	}
+	if p.Score() != 0 {
+		t.Fatalf("the peer must not be penalized, got score %d", p.Score())
+	}
+}
@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"dummy-chain/p2p"
+	"dummy-chain/storage"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+var ErrP2pDisabled = errors.New("peer-to-peer network is disabled")
+
+// PeerManager is the peer-to-peer server of the node
+type PeerManager interface {
+	Peers() []p2p.PeerInfo
+	AddPeer(address string) error
+}
+
+type AdminService struct {
+	storage   *storage.BadgerDb
+	backupDir string
+	// nil when the node doesn't join the peer-to-peer network
+	peers PeerManager
+}
+
+func NewAdminService(db *storage.BadgerDb, backupDir string, peers PeerManager) *AdminService {
+	return &AdminService{
+		storage:   db,
+		backupDir: backupDir,
+		peers:     peers,
+	}
+}
+
+type BackupInfo struct {
+	Path    string
+	Version uint64
+	Size    int64
+}
+
+// Backup writes a snapshot of the running database to the backup directory of the node
+// Only a file name is accepted, an empty name generates one from the current time
+func (a *AdminService) Backup(name string, reply *BackupInfo) error {
+	path, err := storage.BackupFilePath(a.backupDir, name)
+	if err != nil {
+		return err
+	}
+	version, err := a.storage.BackupToFile(path)
+	if err != nil {
+		return err
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	*reply = BackupInfo{
+		Path:    path,
+		Version: version,
+		Size:    stat.Size(),
+	}
+	return nil
+}
+
+// CacheStats reports the size and hit/miss counters of the storage read caches
+func (a *AdminService) CacheStats(param *struct{}, reply *map[string]storage.CacheStats) error {
+	*reply = a.storage.CacheStats()
+	return nil
+}
+
+// Peers lists the connected peers with their height and score
+func (a *AdminService) Peers(param *struct{}, reply *[]p2p.PeerInfo) error {
+	if a.peers == nil {
+		return ErrP2pDisabled
+	}
+	*reply = a.peers.Peers()
+	return nil
+}
+
+// AddPeer connects to a host:port and keeps it as a static peer
+func (a *AdminService) AddPeer(address string, reply *bool) error {
+	if a.peers == nil {
+		return ErrP2pDisabled
+	}
+	if err := a.peers.AddPeer(address); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidParams, err.Error())
+	}
+	*reply = true
+	return nil
+}
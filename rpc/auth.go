@@ -0,0 +1,383 @@
+package rpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"dummy-chain/common/config"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Permission string
+
+const (
+	ReadPermission  Permission = "read"
+	SendPermission  Permission = "send"
+	AdminPermission Permission = "admin"
+)
+
+const (
+	// JWTs must be issued this close to now, like the Ethereum engine API
+	jwtMaxClockDrift = 60 * time.Second
+	jwtMinSecretSize = 32
+
+	tokenQueryParam = "access_token"
+)
+
+// Methods outside of these tables only read
+var (
+	sendMethods = map[string]bool{
+		"chain.SendTransaction":  true,
+		"eth_sendRawTransaction": true,
+	}
+	adminNamespaces = []string{"admin."}
+)
+
+// methodPermission is the permission a JSON-RPC method needs
+func methodPermission(method string) Permission {
+	for _, prefix := range adminNamespaces {
+		if strings.HasPrefix(method, prefix) {
+			return AdminPermission
+		}
+	}
+	if sendMethods[method] {
+		return SendPermission
+	}
+	return ReadPermission
+}
+
+type permissionSet map[Permission]bool
+
+func newPermissionSet(names []string) (permissionSet, error) {
+	set := make(permissionSet)
+	for _, name := range names {
+		permission := Permission(name)
+		switch permission {
+		case ReadPermission, SendPermission, AdminPermission:
+			set[permission] = true
+		default:
+			return nil, errors.Errorf("unknown permission %s", name)
+		}
+	}
+	return set, nil
+}
+
+func (p permissionSet) union(other permissionSet) permissionSet {
+	result := make(permissionSet, len(p)+len(other))
+	for permission := range p {
+		result[permission] = true
+	}
+	for permission := range other {
+		result[permission] = true
+	}
+	return result
+}
+
+// caller is who sent a request, stored in the request context
+type caller struct {
+	permissions   permissionSet
+	authenticated bool
+	// Hash of the token the caller authenticated with, rate limits are per API key instead of per IP
+	apiKey string
+}
+
+type callerKey struct{}
+
+func withCaller(ctx context.Context, c *caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, c)
+}
+
+func callerFrom(ctx context.Context) *caller {
+	if c, ok := ctx.Value(callerKey{}).(*caller); ok {
+		return c
+	}
+	return &caller{}
+}
+
+// check returns ErrUnauthorized when the caller lacks permission
+func (c *caller) check(permission Permission, what string) error {
+	if c.permissions[permission] {
+		return nil
+	}
+	return fmt.Errorf("%w: %s needs the %s permission", ErrUnauthorized, what, permission)
+}
+
+type tokenGrant struct {
+	token       []byte
+	permissions permissionSet
+}
+
+// authenticator turns the credentials of a request into permissions
+type authenticator struct {
+	enabled               bool
+	public                permissionSet
+	tokens                []tokenGrant
+	jwtSecret             []byte
+	jwtPermissions        permissionSet
+	clientCas             *x509.CertPool
+	clientCertPermissions permissionSet
+}
+
+func newAuthenticator(cfg config.AuthConfig) (*authenticator, error) {
+	a := &authenticator{enabled: cfg.Enabled}
+	if !a.enabled {
+		return a, nil
+	}
+
+	var err error
+	if a.public, err = newPermissionSet(cfg.Public); err != nil {
+		return nil, errors.Wrap(err, "Auth.Public")
+	}
+	for token, names := range cfg.Tokens {
+		if token == "" {
+			return nil, errors.New("Auth.Tokens: empty token")
+		}
+		permissions, err := newPermissionSet(names)
+		if err != nil {
+			return nil, errors.Wrap(err, "Auth.Tokens")
+		}
+		a.tokens = append(a.tokens, tokenGrant{token: []byte(token), permissions: permissions})
+	}
+
+	if cfg.JwtSecretFile != "" {
+		if a.jwtSecret, err = readJwtSecret(cfg.JwtSecretFile); err != nil {
+			return nil, err
+		}
+		if a.jwtPermissions, err = newPermissionSet(cfg.JwtPermissions); err != nil {
+			return nil, errors.Wrap(err, "Auth.JwtPermissions")
+		}
+	}
+
+	if cfg.ClientCaFile != "" {
+		data, err := os.ReadFile(cfg.ClientCaFile)
+		if err != nil {
+			return nil, err
+		}
+		a.clientCas = x509.NewCertPool()
+		if !a.clientCas.AppendCertsFromPEM(data) {
+			return nil, errors.Errorf("no certificate found in %s", cfg.ClientCaFile)
+		}
+		if a.clientCertPermissions, err = newPermissionSet(cfg.ClientCertPermissions); err != nil {
+			return nil, errors.Wrap(err, "Auth.ClientCertPermissions")
+		}
+	}
+	return a, nil
+}
+
+func readJwtSecret(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid JWT secret in %s", path)
+	}
+	if len(secret) < jwtMinSecretSize {
+		return nil, errors.Errorf("JWT secret in %s must be at least %d bytes", path, jwtMinSecretSize)
+	}
+	return secret, nil
+}
+
+// authenticate resolves the permissions of an HTTP request
+// A bearer token may also be passed as the access_token query parameter, browsers can't set headers on websockets and event sources
+func (a *authenticator) authenticate(r *http.Request) (*caller, error) {
+	token := bearerToken(r.Header.Get("Authorization"))
+	if token == "" {
+		token = r.URL.Query().Get(tokenQueryParam)
+	}
+	c, err := a.authenticateToken(token, r.RemoteAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	a.authenticateClientCert(c, r.TLS)
+	return c, nil
+}
+
+// authenticateClientCert adds the permissions of a client certificate verified against Auth.ClientCaFile
+func (a *authenticator) authenticateClientCert(c *caller, state *tls.ConnectionState) {
+	if a.enabled && a.clientCas != nil && state != nil && len(state.VerifiedChains) > 0 {
+		c.permissions = c.permissions.union(a.clientCertPermissions)
+		c.authenticated = true
+	}
+}
+
+// tlsConfig is the server TLS config accepting the client certificates of Auth.ClientCaFile, if any
+// Certificates are optional so that token and public callers can still connect
+func (a *authenticator) tlsConfig() *tls.Config {
+	if a.clientCas == nil {
+		return &tls.Config{}
+	}
+	return &tls.Config{
+		ClientCAs:  a.clientCas,
+		ClientAuth: tls.VerifyClientCertIfGiven,
+	}
+}
+
+// authenticateToken resolves the permissions of an optional bearer token sent from remoteAddr, an unknown token is an error
+// Without auth everyone may read and send, admin calls are left to the local machine
+func (a *authenticator) authenticateToken(token string, remoteAddr string) (*caller, error) {
+	if !a.enabled {
+		permissions := permissionSet{ReadPermission: true, SendPermission: true}
+		if isLoopback(remoteAddr) {
+			permissions[AdminPermission] = true
+		}
+		return &caller{permissions: permissions, authenticated: true}, nil
+	}
+
+	c := &caller{permissions: a.public}
+	if token == "" {
+		return c, nil
+	}
+
+	var granted permissionSet
+	for _, grant := range a.tokens {
+		if subtle.ConstantTimeCompare(grant.token, []byte(token)) == 1 {
+			granted = grant.permissions
+			c.apiKey = apiKeyOf(token)
+		}
+	}
+	if granted == nil && a.jwtSecret != nil && strings.Count(token, ".") == 2 {
+		if err := verifyJwt(token, a.jwtSecret, time.Now()); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnauthorized, err.Error())
+		}
+		granted = a.jwtPermissions
+		// A JWT is reissued every minute, all of them share the secret and one key
+		c.apiKey = "jwt"
+	}
+	if granted == nil {
+		return nil, fmt.Errorf("%w: invalid token", ErrUnauthorized)
+	}
+
+	c.permissions = c.permissions.union(granted)
+	c.authenticated = true
+	return c, nil
+}
+
+// handler authenticates every request and stores the caller in its context
+func (a *authenticator) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := a.authenticate(r)
+		if err != nil {
+			writeAuthError(w, false, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(withCaller(r.Context(), c)))
+	})
+}
+
+// require rejects callers without permission before the handler runs
+func require(permission Permission, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c := callerFrom(r.Context())
+		if err := c.check(permission, r.URL.Path); err != nil {
+			writeAuthError(w, c.authenticated, err)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// writeAuthError answers 401 to anonymous callers, so they know to authenticate, and 403 to the others
+func writeAuthError(w http.ResponseWriter, authenticated bool, err error) {
+	status := http.StatusForbidden
+	if !authenticated {
+		status = http.StatusUnauthorized
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+	writeRestJson(w, status, RestError{Error: toError(err)})
+}
+
+// isLoopback tells whether a host:port remote address is on the local machine
+func isLoopback(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func bearerToken(header string) string {
+	const prefix = "bearer "
+	if len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+		return strings.TrimSpace(header[len(prefix):])
+	}
+	return ""
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Iat *int64 `json:"iat"`
+	Exp *int64 `json:"exp"`
+}
+
+// verifyJwt checks an HS256 token with an iat claim within jwtMaxClockDrift of now and an optional exp claim
+func verifyJwt(token string, secret []byte, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errors.New("malformed JWT")
+	}
+
+	var header jwtHeader
+	if err := decodeJwtPart(parts[0], &header); err != nil {
+		return err
+	}
+	if header.Alg != "HS256" {
+		return errors.Errorf("unsupported JWT algorithm %s", header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return errors.New("malformed JWT signature")
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return errors.New("invalid JWT signature")
+	}
+
+	var claims jwtClaims
+	if err = decodeJwtPart(parts[1], &claims); err != nil {
+		return err
+	}
+	if claims.Iat == nil {
+		return errors.New("JWT has no iat claim")
+	}
+	issuedAt := time.Unix(*claims.Iat, 0)
+	if issuedAt.Before(now.Add(-jwtMaxClockDrift)) || issuedAt.After(now.Add(jwtMaxClockDrift)) {
+		return errors.New("JWT iat is too far from the current time")
+	}
+	if claims.Exp != nil && !now.Before(time.Unix(*claims.Exp, 0)) {
+		return errors.New("JWT has expired")
+	}
+	return nil
+}
+
+func decodeJwtPart(part string, value interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return errors.New("malformed JWT")
+	}
+	if err = json.Unmarshal(data, value); err != nil {
+		return errors.New("malformed JWT")
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package rpc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testJwtSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func signTestJwt(header, claims string, secret []byte) string {
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." + base64.RawURLEncoding.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyJwt(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	hs256 := `{"alg":"HS256","typ":"JWT"}`
+	valid := signTestJwt(hs256, `{"iat":1700000000}`, testJwtSecret)
+
+	tests := []struct {
+		name  string
+		token string
+		err   string
+	}{
+		{"valid", valid, ""},
+		{"within clock drift", signTestJwt(hs256, `{"iat":1699999950}`, testJwtSecret), ""},
+		{"not expired", signTestJwt(hs256, `{"iat":1700000000,"exp":1700000060}`, testJwtSecret), ""},
+		{"wrong secret", signTestJwt(hs256, `{"iat":1700000000}`, []byte("another secret of thirty two b!!")), "invalid JWT signature"},
+		{"tampered claims", strings.Replace(valid, strings.Split(valid, ".")[1], base64.RawURLEncoding.EncodeToString([]byte(`{"iat":1700000001}`)), 1), "invalid JWT signature"},
+		{"none algorithm", signTestJwt(`{"alg":"none"}`, `{"iat":1700000000}`, testJwtSecret), "unsupported JWT algorithm none"},
+		{"missing iat", signTestJwt(hs256, `{}`, testJwtSecret), "JWT has no iat claim"},
+		{"iat too old", signTestJwt(hs256, `{"iat":1699999000}`, testJwtSecret), "JWT iat is too far from the current time"},
+		{"iat in the future", signTestJwt(hs256, `{"iat":1700001000}`, testJwtSecret), "JWT iat is too far from the current time"},
+		{"expired", signTestJwt(hs256, `{"iat":1700000000,"exp":1700000000}`, testJwtSecret), "JWT has expired"},
+		{"two parts", "a.b", "malformed JWT"},
+		{"invalid base64", "!.!.!", "malformed JWT"},
+		{"invalid signature encoding", strings.Join(strings.Split(valid, ".")[:2], ".") + ".!", "malformed JWT signature"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := verifyJwt(test.token, testJwtSecret, now)
+			switch {
+			case test.err == "" && err != nil:
+				t.Fatalf("unexpected error %v", err)
+			case test.err != "" && err == nil:
+				t.Fatalf("expected error %q", test.err)
+			case test.err != "" && err.Error() != test.err:
+				t.Fatalf("expected error %q, got %q", test.err, err.Error())
+			}
+		})
+	}
+}
+
+func TestMethodPermission(t *testing.T) {
+	tests := map[string]Permission{
+		"chain.GetAccountInfo":    ReadPermission,
+		"chain.GetBlocksInterval": ReadPermission,
+		"eth_getBalance":          ReadPermission,
+		"chain.SendTransaction":   SendPermission,
+		"eth_sendRawTransaction":  SendPermission,
+		"admin.Backup":            AdminPermission,
+		"admin.Peers":             AdminPermission,
+		// Only the namespace prefix grants admin
+		"chain.admin.Backup": ReadPermission,
+		"":                   ReadPermission,
+	}
+	for method, expected := range tests {
+		if permission := methodPermission(method); permission != expected {
+			t.Errorf("%q: expected %s, got %s", method, expected, permission)
+		}
+	}
+}
+
+func TestAuthenticateClientCert(t *testing.T) {
+	a := &authenticator{
+		enabled:               true,
+		public:                permissionSet{ReadPermission: true},
+		clientCas:             x509.NewCertPool(),
+		clientCertPermissions: permissionSet{SendPermission: true, AdminPermission: true},
+	}
+
+	c, err := a.authenticateToken("", "192.0.2.1:1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.authenticateClientCert(c, &tls.ConnectionState{})
+	if c.authenticated || c.permissions[SendPermission] {
+		t.Fatal("an unverified connection must keep the public permissions")
+	}
+
+	verified := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+	a.authenticateClientCert(c, verified)
+	if !c.authenticated || !c.permissions[ReadPermission] || !c.permissions[SendPermission] || !c.permissions[AdminPermission] {
+		t.Fatalf("a verified certificate must add its permissions, got %v", c.permissions)
+	}
+	if a.public[SendPermission] {
+		t.Fatal("the public permissions must not be modified")
+	}
+}
+
+func TestAuthDisabledAdminLoopback(t *testing.T) {
+	a := &authenticator{}
+	tests := map[string]bool{
+		"127.0.0.1:5000":   true,
+		"[::1]:5000":       true,
+		"192.0.2.1:5000":   false,
+		"[2001:db8::1]:80": false,
+		"":                 false,
+	}
+	for remoteAddr, admin := range tests {
+		c, err := a.authenticateToken("", remoteAddr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !c.permissions[ReadPermission] || !c.permissions[SendPermission] {
+			t.Errorf("%q: read and send must be granted without auth", remoteAddr)
+		}
+		if c.permissions[AdminPermission] != admin {
+			t.Errorf("%q: expected admin %v, got %v", remoteAddr, admin, c.permissions[AdminPermission])
+		}
+	}
+}
@@ -2,57 +2,330 @@ package rpc
 
 import (
 	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"dummy-chain/common"
 	"dummy-chain/common/types"
+	"dummy-chain/p2p"
+	"dummy-chain/storage"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math/big"
 	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
 )
 
+// Timeout of a call when the client is built without WithTimeout
+const defaultClientTimeout = 30 * time.Second
+
+// Client calls the JSON-RPC API of a validator, it is safe for concurrent use
+// Errors returned by the server are *Error values, which unwrap to their sentinel:
+// errors.Is(err, common.ErrNotFound), errors.Is(err, ErrRateLimited) and so on
+// With several endpoints the first healthy one is used, failed reads and submissions are retried on the next one
 type Client struct {
-	url    string
-	client *http.Client
+	endpoints           *endpointSet
+	token               string
+	timeout             time.Duration
+	retries             int
+	healthCheckInterval time.Duration
+	httpClient          *http.Client
+	dialer              *websocket.Dialer
+	nextId              atomic.Uint64
+	stopHealthCheck     context.CancelFunc
+}
+
+type ClientOption func(c *Client)
+
+// WithHttpClient sends the calls through httpClient, to set a proxy or TLS options
+func WithHttpClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout bounds every attempt of a call, 0 leaves it to the context
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithAuthToken sends token as a bearer token with every call and subscription
+func WithAuthToken(token string) ClientOption {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithFallbackUrls adds endpoints used in order when the previous ones fail
+func WithFallbackUrls(urls ...string) ClientOption {
+	return func(c *Client) {
+		for _, u := range urls {
+			c.endpoints.endpoints = append(c.endpoints.endpoints, &endpoint{url: u})
+		}
+	}
+}
+
+// WithRetries sets how many times a retryable call is sent again after a transient error, 0 disables retries
+func WithRetries(retries int) ClientOption {
+	return func(c *Client) {
+		c.retries = retries
+	}
+}
+
+// WithBackoff sets the exponential backoff between retries, and how long a failed endpoint is skipped
+// The wait before retry n is random up to base * 2^n, capped at max
+func WithBackoff(base, max time.Duration) ClientOption {
+	return func(c *Client) {
+		c.endpoints.backoff = base
+		c.endpoints.maxBackoff = max
+	}
+}
+
+// WithHealthCheckInterval sets how often failed endpoints are probed when there are several, 0 disables the probes
+func WithHealthCheckInterval(interval time.Duration) ClientOption {
+	return func(c *Client) {
+		c.healthCheckInterval = interval
+	}
+}
+
+func NewClient(rawUrl string, options ...ClientOption) (*Client, error) {
+	c := &Client{
+		endpoints: &endpointSet{
+			endpoints:  []*endpoint{{url: rawUrl}},
+			backoff:    defaultClientBackoff,
+			maxBackoff: defaultClientMaxBackoff,
+		},
+		timeout:             defaultClientTimeout,
+		retries:             defaultClientRetries,
+		healthCheckInterval: defaultHealthCheckInterval,
+		httpClient:          http.DefaultClient,
+		dialer:              websocket.DefaultDialer,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	for _, e := range c.endpoints.endpoints {
+		u, err := url.Parse(e.url)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, errors.Errorf("unsupported rpc url %s", e.url)
+		}
+	}
+	if transport, ok := c.httpClient.Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+		// Subscriptions trust the same certificates as calls
+		dialer := *websocket.DefaultDialer
+		dialer.TLSClientConfig = transport.TLSClientConfig
+		c.dialer = &dialer
+	}
+
+	if len(c.endpoints.endpoints) > 1 && c.healthCheckInterval > 0 {
+		var ctx context.Context
+		ctx, c.stopHealthCheck = context.WithCancel(context.Background())
+		go c.checkHealth(ctx, c.healthCheckInterval)
+	}
+	return c, nil
+}
+
+// Close stops the health checks, calls in progress are not affected
+func (c *Client) Close() {
+	if c.stopHealthCheck != nil {
+		c.stopHealthCheck()
+	}
+}
+
+// HttpError is returned when the server answers with an HTTP error status
+// Err holds the JSON-RPC error of the reply when there is one, RetryAfter is set for rate limited calls
+type HttpError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Err        *Error
 }
 
-func NewClient(url string) (*Client, error) {
-	return &Client{
-		url:    url,
-		client: http.DefaultClient,
-	}, nil
+func (e *HttpError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Err.Error())
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+func (e *HttpError) Unwrap() error {
+	if e.Err == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// Call invokes method with param and decodes its result into result, which may be nil
+// Each call has its own id so the client can be shared by goroutines
+// Transient failures of retryable methods are retried with a jittered exponential backoff, switching endpoints when one fails
+func (c *Client) Call(ctx context.Context, method string, param interface{}, result interface{}) error {
+	for attempt := 0; ; attempt++ {
+		e := c.endpoints.pick()
+		err := c.callEndpoint(ctx, e, method, param, result)
+		if err == nil {
+			c.endpoints.succeeded(e)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+
+		failover, retry, wait := transientError(err)
+		if failover {
+			c.endpoints.failed(e)
+		}
+		if !retry || !retryable(method) || attempt >= c.retries {
+			return err
+		}
+		if delay := jitteredBackoff(attempt, c.endpoints.backoff, c.endpoints.maxBackoff); delay > wait {
+			wait = delay
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
 }
 
-func (c *Client) Call(method string, param interface{}, result interface{}) error {
+// callEndpoint sends a single attempt of the call to e
+func (c *Client) callEndpoint(ctx context.Context, e *endpoint, method string, param interface{}, result interface{}) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	id := c.nextId.Add(1)
 	reqBody := rpcRequest{
 		JsonRpc: "2.0",
 		Method:  method,
 		Params:  []interface{}{param},
-		Id:      1,
+		Id:      id,
 	}
-
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
 		return err
 	}
 
-	resp, err := c.client.Post(c.url, "application/json", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.authorize(req.Header)
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return httpError(resp, body)
+	}
+
 	var rpcResp rpcResponse
-	if err = json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+	if err = json.Unmarshal(body, &rpcResp); err != nil {
 		return err
 	}
 	if rpcResp.Error != nil {
-		return fmt.Errorf("RPC Error: %v", rpcResp.Error)
+		return rpcResp.Error
+	}
+	if rpcResp.Id != id {
+		return errors.Errorf("reply id %d doesn't match request id %d", rpcResp.Id, id)
+	}
+	if result == nil {
+		return nil
 	}
-
 	return json.Unmarshal(rpcResp.Result, result)
 }
 
-func (c *Client) GetBlocksInterval(left, right uint64) (*types.BlockInfoList, error) {
+func (c *Client) authorize(header http.Header) {
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+}
+
+// httpError keeps the JSON-RPC or REST error carried by an error status
+func httpError(resp *http.Response, body []byte) error {
+	result := &HttpError{StatusCode: resp.StatusCode}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		result.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	var rpcResp rpcResponse
+	if json.Unmarshal(body, &rpcResp) == nil && rpcResp.Error != nil {
+		result.Err = rpcResp.Error
+		return result
+	}
+	var restErr RestError
+	if json.Unmarshal(body, &restErr) == nil && restErr.Error != nil {
+		result.Err = restErr.Error
+	}
+	return result
+}
+
+func (c *Client) GetAccountInfo(ctx context.Context, address ecommon.Address) (*types.AccountInfo, error) {
+	var account types.AccountInfo
+	if err := c.Call(ctx, "chain.GetAccountInfo", address, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (c *Client) GetTransactionByHash(ctx context.Context, hash ecommon.Hash) (*types.TransactionInfo, error) {
+	var tx types.TransactionInfo
+	if err := c.Call(ctx, "chain.GetTransactionByHash", hash, &tx); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+func (c *Client) GetCurrentBlockHeight(ctx context.Context) (uint64, error) {
+	var height uint64
+	if err := c.Call(ctx, "chain.GetCurrenBlockHeight", nil, &height); err != nil {
+		return 0, err
+	}
+	return height, nil
+}
+
+func (c *Client) GetBlockByHash(ctx context.Context, hash ecommon.Hash) (*types.BlockInfo, error) {
+	var block types.BlockInfo
+	if err := c.Call(ctx, "chain.GetBlockByHash", hash, &block); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (c *Client) GetBlockByHeight(ctx context.Context, height uint64) (*types.BlockInfo, error) {
+	var block types.BlockInfo
+	if err := c.Call(ctx, "chain.GetBlockByHeight", height, &block); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// GetBlocksInterval returns the blocks between left and right inclusive, at most common.MaxBlocksInterval of them
+func (c *Client) GetBlocksInterval(ctx context.Context, left, right uint64) (*types.BlockInfoList, error) {
 	var list types.BlockInfoList
-	err := c.Call("chain.GetBlocksInterval", BlockInterval{
+	err := c.Call(ctx, "chain.GetBlocksInterval", BlockInterval{
 		Left:  left,
 		Right: right,
 	}, &list)
@@ -62,38 +335,124 @@ func (c *Client) GetBlocksInterval(left, right uint64) (*types.BlockInfoList, er
 	return &list, nil
 }
 
-func (c *Client) GetAccountInfo(address string) (*types.AccountInfo, error) {
-	var account types.AccountInfo
-	err := c.Call("chain.GetAccountInfo", address, &account)
+// GetHeadersInterval returns the headers between left and right inclusive, at most common.MaxHeadersInterval of them
+func (c *Client) GetHeadersInterval(ctx context.Context, left, right uint64) (*types.HeaderInfoList, error) {
+	var list types.HeaderInfoList
+	err := c.Call(ctx, "chain.GetHeadersInterval", BlockInterval{
+		Left:  left,
+		Right: right,
+	}, &list)
 	if err != nil {
 		return nil, err
 	}
-	return &account, nil
+	return &list, nil
 }
 
-func (c *Client) GetCurrenBlockHeight() (uint64, error) {
-	var height uint64
-	err := c.Call("chain.GetCurrenBlockHeight", nil, &height)
+func (c *Client) SyncStatus(ctx context.Context) (*types.SyncStatus, error) {
+	var status types.SyncStatus
+	if err := c.Call(ctx, "chain.SyncStatus", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// GetAccountTransactions returns the transactions sent or received by address, newest first
+func (c *Client) GetAccountTransactions(ctx context.Context, address ecommon.Address, offset, limit int) (*types.TransactionInfoList, error) {
+	var list types.TransactionInfoList
+	err := c.Call(ctx, "chain.GetAccountTransactions", AccountTransactionsQuery{
+		Address: address,
+		Offset:  offset,
+		Limit:   limit,
+	}, &list)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return height, nil
+	return &list, nil
 }
 
-func (c *Client) SendTransaction(base64Tx string) error {
+// SendRawTransaction submits a base64 gob encoded signed transaction
+// The server ignores a transaction it already has, so a submission is retried like a read
+func (c *Client) SendRawTransaction(ctx context.Context, base64Tx string) error {
 	reply := false
-	return c.Call("chain.SendTransaction", base64Tx, &reply)
+	return c.Call(ctx, "chain.SendTransaction", base64Tx, &reply)
+}
+
+// SendTransaction submits a signed transaction
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	base64Tx, err := tx.EncodeBase64()
+	if err != nil {
+		return err
+	}
+	return c.SendRawTransaction(ctx, base64Tx)
+}
+
+// BuildTransaction prepares an unsigned transfer from the current nonce of the sender
+// It fails with common.ErrNotEnoughBalanceUser when the committed balance can't cover value
+func (c *Client) BuildTransaction(ctx context.Context, from, to ecommon.Address, value *big.Int) (*types.Transaction, error) {
+	account, err := c.GetAccountInfo(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	if value.Cmp(account.BalanceRaw) > 0 {
+		return nil, common.ErrNotEnoughBalanceUser
+	}
+	return types.NewTransaction(from, account.Nonce, to, value), nil
+}
+
+// Transfer builds, signs with key and submits a transfer of value to to, it returns the submitted transaction
+func (c *Client) Transfer(ctx context.Context, key *ecdsa.PrivateKey, to ecommon.Address, value *big.Int) (*types.Transaction, error) {
+	tx, err := c.BuildTransaction(ctx, crypto.PubkeyToAddress(key.PublicKey), to, value)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Sign(key); err != nil {
+		return nil, err
+	}
+	if err = c.SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (c *Client) Backup(ctx context.Context, name string) (*BackupInfo, error) {
+	var info BackupInfo
+	if err := c.Call(ctx, "admin.Backup", name, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (c *Client) CacheStats(ctx context.Context) (map[string]storage.CacheStats, error) {
+	var stats map[string]storage.CacheStats
+	if err := c.Call(ctx, "admin.CacheStats", nil, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (c *Client) Peers(ctx context.Context) ([]p2p.PeerInfo, error) {
+	var peers []p2p.PeerInfo
+	if err := c.Call(ctx, "admin.Peers", nil, &peers); err != nil {
+		return nil, err
+	}
+	return peers, nil
+}
+
+// AddPeer has the node connect to address, a host:port, and keep it as a static peer
+func (c *Client) AddPeer(ctx context.Context, address string) error {
+	var added bool
+	return c.Call(ctx, "admin.AddPeer", address, &added)
 }
 
 type rpcRequest struct {
 	JsonRpc string      `json:"jsonrpc"`
 	Method  string      `json:"method"`
 	Params  interface{} `json:"params"`
-	Id      int         `json:"id"`
+	Id      uint64      `json:"id"`
 }
 
 type rpcResponse struct {
 	Result json.RawMessage `json:"result"`
-	Error  interface{}     `json:"error"`
-	Id     int             `json:"id"`
+	Error  *Error          `json:"error"`
+	Id     uint64          `json:"id"`
 }
@@ -0,0 +1,85 @@
+package rpc
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// How long browsers may cache a preflight answer, in seconds
+const corsMaxAge = 600
+
+// corsPolicy lets the configured browser origins call the server
+type corsPolicy struct {
+	origins   map[string]bool
+	anyOrigin bool
+}
+
+func newCorsPolicy(origins []string) *corsPolicy {
+	policy := &corsPolicy{
+		origins: make(map[string]bool),
+	}
+	for _, origin := range origins {
+		if origin == "*" {
+			policy.anyOrigin = true
+		}
+		policy.origins[strings.TrimSuffix(origin, "/")] = true
+	}
+	return policy
+}
+
+func (c *corsPolicy) allowed(origin string) bool {
+	return c.anyOrigin || c.origins[origin]
+}
+
+// handler adds the CORS headers for allowed origins and answers preflight requests
+// Requests from other origins are refused, a browser sends simple requests without a preflight
+// and hiding the reply from the page doesn't undo what the call did
+func (c *corsPolicy) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		if !c.allowed(origin) {
+			if !sameOrigin(r, origin) {
+				http.Error(w, "origin not allowed", http.StatusForbidden)
+				return
+			}
+			// Pages of the server itself need no CORS headers
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+			}
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(corsMaxAge))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkWebSocketOrigin accepts non browser clients, same origin pages and the allowed origins
+func (c *corsPolicy) checkWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || c.allowed(origin) || sameOrigin(r, origin)
+}
+
+// sameOrigin tells whether origin is the host r was sent to
+func sameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCorsHandler(t *testing.T) {
+	policy := newCorsPolicy([]string{"https://explorer.example.com/"})
+	called := false
+	handler := policy.handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		origin string
+		status int
+		called bool
+	}{
+		{"no origin", http.MethodPost, "", http.StatusOK, true},
+		{"allowed origin", http.MethodPost, "https://explorer.example.com", http.StatusOK, true},
+		{"same origin", http.MethodPost, "http://localhost:12345", http.StatusOK, true},
+		// A text/plain POST needs no preflight, refusing only OPTIONS would let it through
+		{"simple request of another origin", http.MethodPost, "https://evil.example.com", http.StatusForbidden, false},
+		{"preflight of another origin", http.MethodOptions, "https://evil.example.com", http.StatusForbidden, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			called = false
+			request := httptest.NewRequest(test.method, "http://localhost:12345/", nil)
+			if test.origin != "" {
+				request.Header.Set("Origin", test.origin)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != test.status || called != test.called {
+				t.Fatalf("expected status %d and called %v, got %d and %v", test.status, test.called, recorder.Code, called)
+			}
+		})
+	}
+}
+
+func TestServeJsonRpcContentType(t *testing.T) {
+	s := &Server{maxBodySize: 1 << 20}
+	for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded", "multipart/form-data; boundary=x"} {
+		request := httptest.NewRequest(http.MethodPost, "/", nil)
+		if contentType != "" {
+			request.Header.Set("Content-Type", contentType)
+		}
+		recorder := httptest.NewRecorder()
+		s.serveJsonRpc(recorder, request)
+		if recorder.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("%q: expected status 415, got %d", contentType, recorder.Code)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package rpc
+
+import (
+	"dummy-chain/common"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/pkg/errors"
+)
+
+// Standard JSON-RPC 2.0 codes followed by the application specific ones
+const (
+	ParseErrorCode     = -32700
+	InvalidRequestCode = -32600
+	MethodNotFoundCode = -32601
+	InvalidParamsCode  = -32602
+	InternalErrorCode  = -32603
+
+	ServerErrorCode        = -32000
+	NotFoundCode           = -32001
+	InvalidTransactionCode = -32002
+	UnauthorizedCode       = -32003
+	// Same code as Ethereum clients use for an exceeded limit
+	RateLimitedCode = -32005
+)
+
+var (
+	ErrParse          = errors.New("parse error")
+	ErrInvalidRequest = errors.New("invalid request")
+	ErrMethodNotFound = errors.New("method not found")
+	ErrInvalidParams  = errors.New("invalid params")
+	ErrInternal       = errors.New("internal error")
+	ErrServer         = errors.New("server error")
+	ErrUnauthorized   = errors.New("unauthorized")
+	ErrRateLimited    = errors.New("rate limited")
+	ErrMemPoolFull    = errors.New("memory pool is full")
+)
+
+// Error is the error object of a JSON-RPC 2.0 response
+// On the client side it unwraps to the matching sentinel, so errors.Is(err, common.ErrNotFound) works
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("RPC Error %d: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	switch e.Code {
+	case ParseErrorCode:
+		return ErrParse
+	case InvalidRequestCode:
+		return ErrInvalidRequest
+	case MethodNotFoundCode:
+		return ErrMethodNotFound
+	case InvalidParamsCode:
+		return ErrInvalidParams
+	case InternalErrorCode:
+		return ErrInternal
+	case NotFoundCode:
+		return common.ErrNotFound
+	case InvalidTransactionCode:
+		return common.ErrInvalidTransaction
+	case UnauthorizedCode:
+		return ErrUnauthorized
+	case RateLimitedCode:
+		return ErrRateLimited
+	}
+	return ErrServer
+}
+
+func newError(code int, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// toError maps an error returned by a service method to its JSON-RPC error object
+func toError(err error) *Error {
+	var rpcErr *Error
+	switch {
+	case errors.As(err, &rpcErr):
+		return rpcErr
+	case errors.Is(err, common.ErrNotFound), errors.Is(err, badger.ErrKeyNotFound):
+		return newError(NotFoundCode, err.Error())
+	case errors.Is(err, common.ErrInvalidTransaction):
+		return newError(InvalidTransactionCode, err.Error())
+	case errors.Is(err, ErrInvalidParams), errors.Is(err, common.ErrIntervalTooLarge):
+		return newError(InvalidParamsCode, err.Error())
+	case errors.Is(err, ErrUnauthorized):
+		return newError(UnauthorizedCode, err.Error())
+	case errors.Is(err, ErrRateLimited):
+		return newError(RateLimitedCode, err.Error())
+	}
+	return newError(ServerErrorCode, err.Error())
+}
@@ -0,0 +1,390 @@
+package rpc
+
+import (
+	"dummy-chain/common"
+	"dummy-chain/common/types"
+	"dummy-chain/storage"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/dgraph-io/badger/v4"
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// Every transfer is accounted as a plain Ethereum value transfer, there are no fees on this chain
+const (
+	ethTransferGas   = 21000
+	ethBlockGasLimit = 30000000
+)
+
+// EthService maps the eth_ namespace used by wallets and Ethereum libraries onto the chain
+type EthService struct {
+	storage *storage.BadgerDb
+	memPool *MemoryPool
+}
+
+func NewEthService(db *storage.BadgerDb, memPool *MemoryPool) *EthService {
+	return &EthService{
+		storage: db,
+		memPool: memPool,
+	}
+}
+
+// NetService implements the net_ namespace some wallets query before anything else
+type NetService struct{}
+
+func (n *NetService) Version(param *struct{}, reply *string) error {
+	*reply = strconv.FormatUint(common.DummyChainId, 10)
+	return nil
+}
+
+// EthAccountQuery is [address, block]
+type EthAccountQuery struct {
+	Address ecommon.Address
+	Block   string
+}
+
+// EthBlockQuery is [block, fullTransactions]
+type EthBlockQuery struct {
+	Block            string
+	FullTransactions bool
+}
+
+// EthBlockHashQuery is [hash, fullTransactions]
+type EthBlockHashQuery struct {
+	Hash             ecommon.Hash
+	FullTransactions bool
+}
+
+type EthBlock struct {
+	Number           hexutil.Uint64  `json:"number"`
+	Hash             ecommon.Hash    `json:"hash"`
+	ParentHash       ecommon.Hash    `json:"parentHash"`
+	Nonce            hexutil.Bytes   `json:"nonce"`
+	Sha3Uncles       ecommon.Hash    `json:"sha3Uncles"`
+	LogsBloom        hexutil.Bytes   `json:"logsBloom"`
+	TransactionsRoot ecommon.Hash    `json:"transactionsRoot"`
+	StateRoot        ecommon.Hash    `json:"stateRoot"`
+	ReceiptsRoot     ecommon.Hash    `json:"receiptsRoot"`
+	Miner            ecommon.Address `json:"miner"`
+	Difficulty       hexutil.Uint64  `json:"difficulty"`
+	TotalDifficulty  hexutil.Uint64  `json:"totalDifficulty"`
+	ExtraData        hexutil.Bytes   `json:"extraData"`
+	Size             hexutil.Uint64  `json:"size"`
+	GasLimit         hexutil.Uint64  `json:"gasLimit"`
+	GasUsed          hexutil.Uint64  `json:"gasUsed"`
+	Timestamp        hexutil.Uint64  `json:"timestamp"`
+	Transactions     []interface{}   `json:"transactions"`
+	Uncles           []ecommon.Hash  `json:"uncles"`
+}
+
+type EthTransaction struct {
+	Hash             ecommon.Hash    `json:"hash"`
+	Nonce            hexutil.Uint64  `json:"nonce"`
+	BlockHash        *ecommon.Hash   `json:"blockHash"`
+	BlockNumber      *hexutil.Uint64 `json:"blockNumber"`
+	TransactionIndex *hexutil.Uint64 `json:"transactionIndex"`
+	From             ecommon.Address `json:"from"`
+	To               ecommon.Address `json:"to"`
+	Value            *hexutil.Big    `json:"value"`
+	Gas              hexutil.Uint64  `json:"gas"`
+	GasPrice         *hexutil.Big    `json:"gasPrice"`
+	Input            hexutil.Bytes   `json:"input"`
+	Type             hexutil.Uint64  `json:"type"`
+	ChainId          *hexutil.Big    `json:"chainId,omitempty"`
+	V                *hexutil.Big    `json:"v"`
+	R                *hexutil.Big    `json:"r"`
+	S                *hexutil.Big    `json:"s"`
+}
+
+type EthReceipt struct {
+	TransactionHash   ecommon.Hash     `json:"transactionHash"`
+	TransactionIndex  hexutil.Uint64   `json:"transactionIndex"`
+	BlockHash         ecommon.Hash     `json:"blockHash"`
+	BlockNumber       hexutil.Uint64   `json:"blockNumber"`
+	From              ecommon.Address  `json:"from"`
+	To                ecommon.Address  `json:"to"`
+	CumulativeGasUsed hexutil.Uint64   `json:"cumulativeGasUsed"`
+	GasUsed           hexutil.Uint64   `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big     `json:"effectiveGasPrice"`
+	ContractAddress   *ecommon.Address `json:"contractAddress"`
+	Logs              []interface{}    `json:"logs"`
+	LogsBloom         hexutil.Bytes    `json:"logsBloom"`
+	Status            hexutil.Uint64   `json:"status"`
+	Type              hexutil.Uint64   `json:"type"`
+}
+
+func (e *EthService) ChainId(param *struct{}, reply *hexutil.Uint64) error {
+	*reply = common.DummyChainId
+	return nil
+}
+
+func (e *EthService) BlockNumber(param *struct{}, reply *hexutil.Uint64) error {
+	height, err := e.storage.GetHeight()
+	if err != nil {
+		return err
+	}
+	*reply = hexutil.Uint64(height)
+	return nil
+}
+
+func (e *EthService) GasPrice(param *struct{}, reply *hexutil.Big) error {
+	*reply = hexutil.Big{}
+	return nil
+}
+
+func (e *EthService) EstimateGas(param *struct{}, reply *hexutil.Uint64) error {
+	*reply = ethTransferGas
+	return nil
+}
+
+// GetCode always returns empty code, there are no contracts on this chain
+func (e *EthService) GetCode(query EthAccountQuery, reply *hexutil.Bytes) error {
+	*reply = hexutil.Bytes{}
+	return nil
+}
+
+func (e *EthService) GetBalance(query EthAccountQuery, reply *hexutil.Big) error {
+	if err := e.checkLatest(query.Block); err != nil {
+		return err
+	}
+	account, err := e.storage.GetAccount(query.Address)
+	if err != nil {
+		return err
+	}
+	*reply = hexutil.Big(*account.Balance)
+	return nil
+}
+
+// GetTransactionCount returns the account nonce, "pending" also counts the transactions waiting in the mempool
+func (e *EthService) GetTransactionCount(query EthAccountQuery, reply *hexutil.Uint64) error {
+	if err := e.checkLatest(query.Block); err != nil {
+		return err
+	}
+	account, err := e.storage.GetAccount(query.Address)
+	if err != nil {
+		return err
+	}
+	nonce := account.Nonce
+	if query.Block == "pending" {
+		nonce = e.memPool.PendingNonce(query.Address, nonce)
+	}
+	*reply = hexutil.Uint64(nonce)
+	return nil
+}
+
+func (e *EthService) GetBlockByNumber(query EthBlockQuery, reply **EthBlock) error {
+	height, err := e.resolveBlock(query.Block)
+	if err != nil {
+		return err
+	}
+	entry, err := e.storage.GetBlockWithTransactionsByHeight(height)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		// Unknown blocks are null, not an error
+		*reply = nil
+		return nil
+	} else if err != nil {
+		return err
+	}
+	*reply = toEthBlock(entry, query.FullTransactions)
+	return nil
+}
+
+func (e *EthService) GetBlockByHash(query EthBlockHashQuery, reply **EthBlock) error {
+	entry, err := e.storage.GetBlockWithTransactionsByHash(query.Hash)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		*reply = nil
+		return nil
+	} else if err != nil {
+		return err
+	}
+	*reply = toEthBlock(entry, query.FullTransactions)
+	return nil
+}
+
+// GetTransactionByHash also finds transactions still waiting in the mempool, those have no block yet
+func (e *EthService) GetTransactionByHash(hash ecommon.Hash, reply **EthTransaction) error {
+	if tx, ok := e.memPool.GetTransaction(hash); ok {
+		*reply = toEthTransaction(tx, nil, 0)
+		return nil
+	}
+
+	tx, err := e.storage.GetTransaction(hash)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		*reply = nil
+		return nil
+	} else if err != nil {
+		return err
+	}
+	block, err := e.storage.GetBlockByHeight(tx.BlockHeight)
+	if err != nil {
+		return err
+	}
+	*reply = toEthTransaction(tx, block, transactionIndex(block, hash))
+	return nil
+}
+
+func (e *EthService) GetTransactionReceipt(hash ecommon.Hash, reply **EthReceipt) error {
+	tx, err := e.storage.GetTransaction(hash)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		*reply = nil
+		return nil
+	} else if err != nil {
+		return err
+	}
+	block, err := e.storage.GetBlockByHeight(tx.BlockHeight)
+	if err != nil {
+		return err
+	}
+	index := transactionIndex(block, hash)
+
+	*reply = &EthReceipt{
+		TransactionHash:   tx.Hash,
+		TransactionIndex:  hexutil.Uint64(index),
+		BlockHash:         block.Hash,
+		BlockNumber:       hexutil.Uint64(block.Height),
+		From:              tx.From,
+		To:                tx.To,
+		CumulativeGasUsed: hexutil.Uint64(ethTransferGas * (index + 1)),
+		GasUsed:           ethTransferGas,
+		EffectiveGasPrice: (*hexutil.Big)(big.NewInt(0)),
+		Logs:              make([]interface{}, 0),
+		LogsBloom:         make(hexutil.Bytes, ethtypes.BloomByteLength),
+		Status:            hexutil.Uint64(ethtypes.ReceiptStatusSuccessful),
+	}
+	return nil
+}
+
+// SendRawTransaction accepts a signed RLP legacy or EIP-155 value transfer and returns its hash
+func (e *EthService) SendRawTransaction(raw hexutil.Bytes, reply *ecommon.Hash) error {
+	tx, err := types.DecodeEthereumTransaction(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %s", common.ErrInvalidTransaction, err.Error())
+	}
+
+	common.GlobalLogger.Debugf("Received ethereum transaction: %s", tx.String())
+	if err = AddToMemPool(e.storage, e.memPool, tx); err != nil {
+		return err
+	}
+	*reply = tx.Hash
+	return nil
+}
+
+// checkLatest rejects queries for historical state, only the current accounts are stored
+func (e *EthService) checkLatest(block string) error {
+	switch block {
+	case "", "latest", "pending", "safe", "finalized":
+		return nil
+	}
+	height, err := e.resolveBlock(block)
+	if err != nil {
+		return err
+	}
+	current, err := e.storage.GetHeight()
+	if err != nil {
+		return err
+	}
+	if height != current {
+		return fmt.Errorf("%w: historical state is not available", ErrInvalidParams)
+	}
+	return nil
+}
+
+func (e *EthService) resolveBlock(block string) (uint64, error) {
+	switch block {
+	case "earliest":
+		return 0, nil
+	case "", "latest", "pending", "safe", "finalized":
+		return e.storage.GetHeight()
+	}
+	height, err := hexutil.DecodeUint64(block)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid block %s", ErrInvalidParams, block)
+	}
+	return height, nil
+}
+
+func transactionIndex(block *types.Block, hash ecommon.Hash) uint64 {
+	for i, txHash := range block.Transactions {
+		if txHash == hash {
+			return uint64(i)
+		}
+	}
+	return 0
+}
+
+func toEthBlock(entry *storage.BlockWithTransactions, fullTransactions bool) *EthBlock {
+	block := entry.Block
+	txs := make([]interface{}, 0, len(entry.Transactions))
+	for i, tx := range entry.Transactions {
+		if fullTransactions {
+			txs = append(txs, toEthTransaction(tx, block, uint64(i)))
+		} else {
+			txs = append(txs, tx.Hash)
+		}
+	}
+
+	return &EthBlock{
+		Number:           hexutil.Uint64(block.Height),
+		Hash:             block.Hash,
+		ParentHash:       block.PrevHash,
+		Nonce:            make(hexutil.Bytes, 8),
+		Sha3Uncles:       ethtypes.EmptyUncleHash,
+		LogsBloom:        make(hexutil.Bytes, ethtypes.BloomByteLength),
+		TransactionsRoot: ethtypes.EmptyTxsHash,
+		StateRoot:        ecommon.Hash{},
+		ReceiptsRoot:     ethtypes.EmptyReceiptsHash,
+		Miner:            block.Validator,
+		ExtraData:        hexutil.Bytes{},
+		GasLimit:         ethBlockGasLimit,
+		GasUsed:          hexutil.Uint64(ethTransferGas * len(entry.Transactions)),
+		Timestamp:        hexutil.Uint64(block.Timestamp),
+		Transactions:     txs,
+		Uncles:           make([]ecommon.Hash, 0),
+	}
+}
+
+// toEthTransaction describes a chain transaction the way Ethereum does, block is nil while it is pending
+func toEthTransaction(tx *types.Transaction, block *types.Block, index uint64) *EthTransaction {
+	result := &EthTransaction{
+		Hash:     tx.Hash,
+		Nonce:    hexutil.Uint64(tx.Nonce),
+		From:     tx.From,
+		To:       tx.To,
+		Value:    (*hexutil.Big)(new(big.Int).Set(tx.Value)),
+		Gas:      ethTransferGas,
+		GasPrice: (*hexutil.Big)(big.NewInt(0)),
+		Input:    hexutil.Bytes{},
+		Type:     ethtypes.LegacyTxType,
+		V:        (*hexutil.Big)(big.NewInt(0)),
+		R:        (*hexutil.Big)(big.NewInt(0)),
+		S:        (*hexutil.Big)(big.NewInt(0)),
+	}
+	if block != nil {
+		blockNumber := hexutil.Uint64(block.Height)
+		transactionIndex := hexutil.Uint64(index)
+		result.BlockHash = &block.Hash
+		result.BlockNumber = &blockNumber
+		result.TransactionIndex = &transactionIndex
+	}
+
+	var ethTx ethtypes.Transaction
+	if len(tx.Raw) > 0 && ethTx.UnmarshalBinary(tx.Raw) == nil {
+		v, r, s := ethTx.RawSignatureValues()
+		result.V, result.R, result.S = (*hexutil.Big)(v), (*hexutil.Big)(r), (*hexutil.Big)(s)
+		result.Gas = hexutil.Uint64(ethTx.Gas())
+		result.GasPrice = (*hexutil.Big)(ethTx.GasPrice())
+		if ethTx.Protected() {
+			result.ChainId = (*hexutil.Big)(ethTx.ChainId())
+		}
+	} else if len(tx.Signature) == 65 {
+		result.R = (*hexutil.Big)(new(big.Int).SetBytes(tx.Signature[:32]))
+		result.S = (*hexutil.Big)(new(big.Int).SetBytes(tx.Signature[32:64]))
+		result.V = (*hexutil.Big)(new(big.Int).SetUint64(uint64(tx.Signature[64]) + 27))
+	}
+	return result
+}
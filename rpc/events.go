@@ -0,0 +1,98 @@
+package rpc
+
+import (
+	"dummy-chain/common/types"
+	"sync"
+)
+
+// Number of events buffered per subscriber, a subscriber that falls further behind is dropped
+const eventBufferSize = 256
+
+type EventKind int
+
+const (
+	NewBlockEvent EventKind = iota
+	PendingTransactionEvent
+)
+
+type Event struct {
+	Kind        EventKind
+	Block       *types.BlockInfo
+	Transaction *types.TransactionInfo
+}
+
+type EventSubscription struct {
+	id uint64
+	C  <-chan Event
+	c  chan Event
+}
+
+// EventBus fans out chain events to every subscriber without ever blocking the publisher
+type EventBus struct {
+	subscribers map[uint64]*EventSubscription
+	nextId      uint64
+	lock        sync.Mutex
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subscribers: make(map[uint64]*EventSubscription),
+		lock:        sync.Mutex{},
+	}
+}
+
+func (bus *EventBus) Subscribe() *EventSubscription {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+
+	c := make(chan Event, eventBufferSize)
+	sub := &EventSubscription{
+		id: bus.nextId,
+		C:  c,
+		c:  c,
+	}
+	bus.nextId += 1
+	bus.subscribers[sub.id] = sub
+	return sub
+}
+
+// Unsubscribe closes the channel of the subscription, it is safe to call more than once
+func (bus *EventBus) Unsubscribe(sub *EventSubscription) {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+
+	if _, ok := bus.subscribers[sub.id]; ok {
+		delete(bus.subscribers, sub.id)
+		close(sub.c)
+	}
+}
+
+// Publish delivers the event to every subscriber
+// Subscribers with a full buffer are dropped and see their channel closed
+func (bus *EventBus) Publish(event Event) {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+
+	for id, sub := range bus.subscribers {
+		select {
+		case sub.c <- event:
+		default:
+			delete(bus.subscribers, id)
+			close(sub.c)
+		}
+	}
+}
+
+func (bus *EventBus) PublishBlock(block types.BlockInfo) {
+	bus.Publish(Event{
+		Kind:  NewBlockEvent,
+		Block: &block,
+	})
+}
+
+func (bus *EventBus) PublishTransaction(tx types.TransactionInfo) {
+	bus.Publish(Event{
+		Kind:        PendingTransactionEvent,
+		Transaction: &tx,
+	})
+}
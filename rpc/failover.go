@@ -0,0 +1,144 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultClientRetries       = 3
+	defaultClientBackoff       = 100 * time.Millisecond
+	defaultClientMaxBackoff    = 5 * time.Second
+	defaultHealthCheckInterval = 10 * time.Second
+)
+
+// endpoint is one of the servers of a client, it is skipped until retryAt after failing
+type endpoint struct {
+	url      string
+	failures int
+	retryAt  time.Time
+}
+
+func (e *endpoint) healthy(now time.Time) bool {
+	return !now.Before(e.retryAt)
+}
+
+// endpointSet prefers the endpoints in the configured order, so traffic returns to the first one once it recovers
+type endpointSet struct {
+	lock       sync.Mutex
+	endpoints  []*endpoint
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// pick returns the first healthy endpoint, or the one that recovers first when all of them failed
+func (s *endpointSet) pick() *endpoint {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	best := s.endpoints[0]
+	for _, e := range s.endpoints {
+		if e.healthy(now) {
+			return e
+		}
+		if e.retryAt.Before(best.retryAt) {
+			best = e
+		}
+	}
+	return best
+}
+
+func (s *endpointSet) failed(e *endpoint) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e.failures += 1
+	e.retryAt = time.Now().Add(jitteredBackoff(e.failures-1, s.backoff, s.maxBackoff))
+}
+
+func (s *endpointSet) succeeded(e *endpoint) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e.failures = 0
+	e.retryAt = time.Time{}
+}
+
+// unhealthy returns the endpoints currently skipped
+func (s *endpointSet) unhealthy() []*endpoint {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	var result []*endpoint
+	for _, e := range s.endpoints {
+		if !e.healthy(now) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// jitteredBackoff is a random duration up to base * 2^attempt capped at max, the full jitter of
+// https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+func jitteredBackoff(attempt int, base, max time.Duration) time.Duration {
+	ceiling := max
+	if attempt < 32 && base<<attempt < max {
+		ceiling = base << attempt
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) + 1
+}
+
+// retryable tells whether a call may be sent again after an error
+// Everything but the admin methods is idempotent, transaction submissions are deduplicated by hash on the server
+func retryable(method string) bool {
+	return methodPermission(method) != AdminPermission
+}
+
+// transientError classifies a failed attempt
+// failover is set when the endpoint itself failed and other endpoints should be preferred,
+// retry when the call may succeed later, with the least time to wait
+func transientError(err error) (failover bool, retry bool, wait time.Duration) {
+	var httpErr *HttpError
+	if errors.As(err, &httpErr) {
+		switch {
+		case httpErr.StatusCode == http.StatusTooManyRequests:
+			return false, true, httpErr.RetryAfter
+		case httpErr.StatusCode >= http.StatusInternalServerError:
+			return true, true, httpErr.RetryAfter
+		}
+		return false, false, 0
+	}
+	// Connection failures and timeouts, the errors of http.Client are net.Error values
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return true, true, 0
+	}
+	return false, false, 0
+}
+
+// checkHealth probes the skipped endpoints until ctx is done, so they come back before their backoff expires
+func (c *Client) checkHealth(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, e := range c.endpoints.unhealthy() {
+				var height uint64
+				if err := c.callEndpoint(ctx, e, "chain.GetCurrenBlockHeight", nil, &height); err == nil {
+					c.endpoints.succeeded(e)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,635 @@
+package rpc
+
+import (
+	"context"
+	"dummy-chain/common"
+	"dummy-chain/common/types"
+	"dummy-chain/storage"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dgraph-io/badger/v4"
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/pkg/errors"
+)
+
+const (
+	graphqlMaxDepth       = 10
+	graphqlMaxParallelism = 10
+	// Blocks, transactions and accounts a query may load, nesting multiplies the lists so depth alone doesn't bound the cost
+	graphqlMaxItems = 2000
+)
+
+var errQueryTooExpensive = fmt.Errorf("%w: the query loads more than %d blocks, transactions and accounts", ErrInvalidParams, graphqlMaxItems)
+
+// Lists default to and are capped at common.MaxAccountTransactions items, height ranges at common.MaxBlocksInterval blocks
+const graphqlSchema = `
+schema {
+	query: Query
+}
+
+# Unsigned 64 bit integer, heights, nonces and timestamps don't fit in Int
+scalar Long
+
+type Query {
+	height: Long!
+	# One of height or hash, the latest block when both are omitted
+	block(height: Long, hash: String): Block
+	# Blocks from fromHeight (default 0) to toHeight (default the tip), at most first of them, oldest first
+	blocks(fromHeight: Long, toHeight: Long, first: Int): [Block!]!
+	transaction(hash: String!): Transaction
+	# Committed transactions, newest first, optionally of a single address
+	# Without an address the height range defaults to the last 100 blocks
+	transactions(address: String, fromHeight: Long, toHeight: Long, first: Int, offset: Int): [Transaction!]!
+	account(address: String!): Account!
+}
+
+type Block {
+	chainId: Long!
+	hash: String!
+	height: Long!
+	timestamp: Long!
+	prevHash: String!
+	parent: Block
+	validator: Account!
+	signature: String!
+	transactionCount: Int!
+	transactions(address: String, first: Int, offset: Int): [Transaction!]!
+}
+
+type Transaction {
+	hash: String!
+	blockHeight: Long!
+	block: Block
+	from: Account!
+	to: Account!
+	nonce: Long!
+	# Base 10 amount in the smallest unit
+	value: String!
+	signature: String!
+	raw: String
+}
+
+type Account {
+	address: String!
+	nonce: Long!
+	# Base 10 amount in the smallest unit
+	balance: String!
+	transactions(fromHeight: Long, toHeight: Long, first: Int, offset: Int): [Transaction!]!
+}
+`
+
+// Long is the GraphQL scalar for uint64 values
+type Long uint64
+
+func (Long) ImplementsGraphQLType(name string) bool {
+	return name == "Long"
+}
+
+func (l *Long) UnmarshalGraphQL(input interface{}) error {
+	switch value := input.(type) {
+	case int32:
+		if value < 0 {
+			return errors.New("Long must not be negative")
+		}
+		*l = Long(value)
+	case int64:
+		if value < 0 {
+			return errors.New("Long must not be negative")
+		}
+		*l = Long(value)
+	case float64:
+		if value < 0 || value != float64(uint64(value)) {
+			return errors.New("Long must be a non negative integer")
+		}
+		*l = Long(value)
+	case string:
+		number, err := strconv.ParseUint(value, 0, 64)
+		if err != nil {
+			return err
+		}
+		*l = Long(number)
+	default:
+		return errors.Errorf("unexpected type %T for Long", input)
+	}
+	return nil
+}
+
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func newGraphqlSchema(db *storage.BadgerDb) (*graphql.Schema, error) {
+	return graphql.ParseSchema(graphqlSchema, &graphqlResolver{storage: db},
+		graphql.MaxDepth(graphqlMaxDepth),
+		graphql.MaxParallelism(graphqlMaxParallelism),
+	)
+}
+
+// serveGraphql accepts a POST JSON body or the query, operationName and variables GET parameters
+func (s *Server) serveGraphql(w http.ResponseWriter, r *http.Request) {
+	var request graphqlRequest
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		request.Query = query.Get("query")
+		request.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				http.Error(w, "invalid variables", http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.maxBodySize)).Decode(&request); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	budget := &graphqlBudget{}
+	ctx := context.WithValue(r.Context(), graphqlBudgetKey{}, budget)
+	response := s.graphqlSchema.Exec(ctx, request.Query, request.OperationName, request.Variables)
+	if budget.items.Load() > graphqlMaxItems {
+		// The partial result is dropped, the query is rejected as a whole
+		response = &graphql.Response{Errors: []*gqlerrors.QueryError{gqlerrors.Errorf("%s", errQueryTooExpensive.Error())}}
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+// graphqlBudget counts the items loaded by one query
+type graphqlBudget struct {
+	items atomic.Int64
+}
+
+type graphqlBudgetKey struct{}
+
+// chargeItems counts n items against the budget of the query, failing once it is exceeded
+func chargeItems(ctx context.Context, n int) error {
+	budget, ok := ctx.Value(graphqlBudgetKey{}).(*graphqlBudget)
+	if !ok {
+		return nil
+	}
+	if budget.items.Add(int64(n)) > graphqlMaxItems {
+		return errQueryTooExpensive
+	}
+	return nil
+}
+
+type graphqlResolver struct {
+	storage *storage.BadgerDb
+}
+
+type blockQueryArgs struct {
+	Height *Long
+	Hash   *string
+}
+
+type blocksQueryArgs struct {
+	FromHeight *Long
+	ToHeight   *Long
+	First      *int32
+}
+
+type transactionsQueryArgs struct {
+	Address    *string
+	FromHeight *Long
+	ToHeight   *Long
+	First      *int32
+	Offset     *int32
+}
+
+type blockTransactionsArgs struct {
+	Address *string
+	First   *int32
+	Offset  *int32
+}
+
+type accountTransactionsArgs struct {
+	FromHeight *Long
+	ToHeight   *Long
+	First      *int32
+	Offset     *int32
+}
+
+func (r *graphqlResolver) Height() (Long, error) {
+	height, err := r.storage.GetHeight()
+	return Long(height), err
+}
+
+func (r *graphqlResolver) Block(ctx context.Context, args blockQueryArgs) (*blockResolver, error) {
+	if args.Height != nil && args.Hash != nil {
+		return nil, fmt.Errorf("%w: height and hash are exclusive", ErrInvalidParams)
+	}
+	if args.Hash != nil {
+		hash, err := parseHash(*args.Hash)
+		if err != nil {
+			return nil, err
+		}
+		return r.blockByHash(ctx, hash)
+	}
+
+	var height uint64
+	if args.Height != nil {
+		height = uint64(*args.Height)
+	} else {
+		var err error
+		if height, err = r.storage.GetHeight(); err != nil {
+			return nil, err
+		}
+	}
+	return r.blockByHeight(ctx, height)
+}
+
+func (r *graphqlResolver) Blocks(ctx context.Context, args blocksQueryArgs) ([]*blockResolver, error) {
+	first, err := pageSize(args.First, common.MaxBlocksInterval)
+	if err != nil {
+		return nil, err
+	}
+	var from uint64
+	if args.FromHeight != nil {
+		from = uint64(*args.FromHeight)
+	}
+	to, err := r.toHeight(args.ToHeight)
+	if err != nil {
+		return nil, err
+	}
+	if to < from {
+		return []*blockResolver{}, nil
+	}
+	if to-from >= uint64(first) {
+		to = from + uint64(first) - 1
+	}
+	if err = chargeItems(ctx, int(to-from+1)); err != nil {
+		return nil, err
+	}
+
+	entries, err := r.storage.GetBlockRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*blockResolver, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, &blockResolver{r: r, entry: entry})
+	}
+	return result, nil
+}
+
+func (r *graphqlResolver) Transaction(ctx context.Context, args struct{ Hash string }) (*transactionResolver, error) {
+	hash, err := parseHash(args.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if err = chargeItems(ctx, 1); err != nil {
+		return nil, err
+	}
+	tx, err := r.storage.GetTransaction(hash)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &transactionResolver{r: r, tx: tx}, nil
+}
+
+func (r *graphqlResolver) Transactions(ctx context.Context, args transactionsQueryArgs) ([]*transactionResolver, error) {
+	first, err := pageSize(args.First, common.MaxAccountTransactions)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := pageOffset(args.Offset)
+	if err != nil {
+		return nil, err
+	}
+	to, err := r.toHeight(args.ToHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Address != nil {
+		address, err := parseAddress(*args.Address)
+		if err != nil {
+			return nil, err
+		}
+		var from uint64
+		if args.FromHeight != nil {
+			from = uint64(*args.FromHeight)
+		}
+		return r.accountTransactions(ctx, address, from, to, offset, first)
+	}
+
+	// Without the account index the blocks of the range are read, so the range is capped
+	var from uint64
+	if args.FromHeight != nil {
+		from = uint64(*args.FromHeight)
+	} else if to >= common.MaxBlocksInterval {
+		from = to - common.MaxBlocksInterval + 1
+	}
+	if to < from {
+		return []*transactionResolver{}, nil
+	}
+	if to-from >= common.MaxBlocksInterval {
+		return nil, common.ErrIntervalTooLarge
+	}
+	if err = chargeItems(ctx, int(to-from+1)); err != nil {
+		return nil, err
+	}
+
+	entries, err := r.storage.GetBlockRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]*types.Transaction, 0)
+	for i := len(entries) - 1; i >= 0; i-- {
+		for j := len(entries[i].Transactions) - 1; j >= 0; j-- {
+			txs = append(txs, entries[i].Transactions[j])
+		}
+	}
+	return r.page(ctx, txs, offset, first)
+}
+
+func (r *graphqlResolver) Account(args struct{ Address string }) (*accountResolver, error) {
+	address, err := parseAddress(args.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &accountResolver{r: r, address: address}, nil
+}
+
+func (r *graphqlResolver) blockByHash(ctx context.Context, hash ecommon.Hash) (*blockResolver, error) {
+	if err := chargeItems(ctx, 1); err != nil {
+		return nil, err
+	}
+	entry, err := r.storage.GetBlockWithTransactionsByHash(hash)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &blockResolver{r: r, entry: entry}, nil
+}
+
+func (r *graphqlResolver) blockByHeight(ctx context.Context, height uint64) (*blockResolver, error) {
+	if err := chargeItems(ctx, 1); err != nil {
+		return nil, err
+	}
+	entry, err := r.storage.GetBlockWithTransactionsByHeight(height)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &blockResolver{r: r, entry: entry}, nil
+}
+
+func (r *graphqlResolver) accountTransactions(ctx context.Context, address ecommon.Address, from, to uint64, offset, first int) ([]*transactionResolver, error) {
+	// Charged before the storage call like the block ranges, a page may hold first transactions
+	if err := chargeItems(ctx, first); err != nil {
+		return nil, err
+	}
+	txs, err := r.storage.GetAccountTransactionsInRange(address, from, to, offset, first)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*transactionResolver, 0, len(txs))
+	for _, tx := range txs {
+		result = append(result, &transactionResolver{r: r, tx: tx})
+	}
+	return result, nil
+}
+
+// toHeight defaults to the current height
+func (r *graphqlResolver) toHeight(value *Long) (uint64, error) {
+	if value != nil {
+		return uint64(*value), nil
+	}
+	return r.storage.GetHeight()
+}
+
+// page returns first transactions from offset, charged to the budget of the query
+func (r *graphqlResolver) page(ctx context.Context, txs []*types.Transaction, offset, first int) ([]*transactionResolver, error) {
+	result := make([]*transactionResolver, 0)
+	for i := offset; i < len(txs) && len(result) < first; i++ {
+		result = append(result, &transactionResolver{r: r, tx: txs[i]})
+	}
+	if err := chargeItems(ctx, len(result)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func pageSize(first *int32, max int) (int, error) {
+	if first == nil {
+		return max, nil
+	}
+	if *first < 0 {
+		return 0, fmt.Errorf("%w: first must not be negative", ErrInvalidParams)
+	}
+	if int(*first) > max {
+		return max, nil
+	}
+	return int(*first), nil
+}
+
+func pageOffset(offset *int32) (int, error) {
+	if offset == nil {
+		return 0, nil
+	}
+	if *offset < 0 {
+		return 0, fmt.Errorf("%w: offset must not be negative", ErrInvalidParams)
+	}
+	return int(*offset), nil
+}
+
+type blockResolver struct {
+	r     *graphqlResolver
+	entry *storage.BlockWithTransactions
+}
+
+func (b *blockResolver) ChainId() Long {
+	return Long(b.entry.Block.ChainId)
+}
+
+func (b *blockResolver) Hash() string {
+	return b.entry.Block.Hash.String()
+}
+
+func (b *blockResolver) Height() Long {
+	return Long(b.entry.Block.Height)
+}
+
+func (b *blockResolver) Timestamp() Long {
+	return Long(b.entry.Block.Timestamp)
+}
+
+func (b *blockResolver) PrevHash() string {
+	return b.entry.Block.PrevHash.String()
+}
+
+func (b *blockResolver) Parent(ctx context.Context) (*blockResolver, error) {
+	if b.entry.Block.Height == 0 {
+		return nil, nil
+	}
+	return b.r.blockByHash(ctx, b.entry.Block.PrevHash)
+}
+
+func (b *blockResolver) Validator() *accountResolver {
+	return &accountResolver{r: b.r, address: b.entry.Block.Validator}
+}
+
+func (b *blockResolver) Signature() string {
+	return base64.StdEncoding.EncodeToString(b.entry.Block.Signature)
+}
+
+func (b *blockResolver) TransactionCount() int32 {
+	return int32(len(b.entry.Transactions))
+}
+
+func (b *blockResolver) Transactions(ctx context.Context, args blockTransactionsArgs) ([]*transactionResolver, error) {
+	first, err := pageSize(args.First, len(b.entry.Transactions))
+	if err != nil {
+		return nil, err
+	}
+	offset, err := pageOffset(args.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := b.entry.Transactions
+	if args.Address != nil {
+		address, err := parseAddress(*args.Address)
+		if err != nil {
+			return nil, err
+		}
+		txs = make([]*types.Transaction, 0)
+		for _, tx := range b.entry.Transactions {
+			if tx.From == address || tx.To == address {
+				txs = append(txs, tx)
+			}
+		}
+	}
+	return b.r.page(ctx, txs, offset, first)
+}
+
+type transactionResolver struct {
+	r  *graphqlResolver
+	tx *types.Transaction
+}
+
+func (t *transactionResolver) Hash() string {
+	return t.tx.Hash.String()
+}
+
+func (t *transactionResolver) BlockHeight() Long {
+	return Long(t.tx.BlockHeight)
+}
+
+func (t *transactionResolver) Block(ctx context.Context) (*blockResolver, error) {
+	return t.r.blockByHeight(ctx, t.tx.BlockHeight)
+}
+
+func (t *transactionResolver) From() *accountResolver {
+	return &accountResolver{r: t.r, address: t.tx.From}
+}
+
+func (t *transactionResolver) To() *accountResolver {
+	return &accountResolver{r: t.r, address: t.tx.To}
+}
+
+func (t *transactionResolver) Nonce() Long {
+	return Long(t.tx.Nonce)
+}
+
+func (t *transactionResolver) Value() string {
+	return t.tx.Value.String()
+}
+
+func (t *transactionResolver) Signature() string {
+	return base64.StdEncoding.EncodeToString(t.tx.Signature)
+}
+
+func (t *transactionResolver) Raw() *string {
+	if len(t.tx.Raw) == 0 {
+		return nil
+	}
+	raw := base64.StdEncoding.EncodeToString(t.tx.Raw)
+	return &raw
+}
+
+// accountResolver loads the account once, only when nonce or balance is asked for
+type accountResolver struct {
+	r       *graphqlResolver
+	address ecommon.Address
+
+	once    sync.Once
+	account *types.Account
+	err     error
+}
+
+func (a *accountResolver) load(ctx context.Context) (*types.Account, error) {
+	a.once.Do(func() {
+		if a.err = chargeItems(ctx, 1); a.err != nil {
+			return
+		}
+		a.account, a.err = a.r.storage.GetAccount(a.address)
+	})
+	return a.account, a.err
+}
+
+func (a *accountResolver) Address() string {
+	return a.address.String()
+}
+
+func (a *accountResolver) Nonce(ctx context.Context) (Long, error) {
+	account, err := a.load(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(account.Nonce), nil
+}
+
+func (a *accountResolver) Balance(ctx context.Context) (string, error) {
+	account, err := a.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	return account.Balance.String(), nil
+}
+
+func (a *accountResolver) Transactions(ctx context.Context, args accountTransactionsArgs) ([]*transactionResolver, error) {
+	first, err := pageSize(args.First, common.MaxAccountTransactions)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := pageOffset(args.Offset)
+	if err != nil {
+		return nil, err
+	}
+	var from uint64
+	if args.FromHeight != nil {
+		from = uint64(*args.FromHeight)
+	}
+	to, err := a.r.toHeight(args.ToHeight)
+	if err != nil {
+		return nil, err
+	}
+	return a.r.accountTransactions(ctx, a.address, from, to, offset, first)
+}
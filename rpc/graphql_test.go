@@ -0,0 +1,101 @@
+package rpc
+
+import (
+	"dummy-chain/common"
+	"dummy-chain/common/config"
+	"dummy-chain/common/types"
+	"dummy-chain/storage"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+)
+
+func newTestGraphqlServer(t *testing.T, height uint64) *Server {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	db, err := storage.NewBadgerDb("test", config.CacheConfig{Blocks: 16, Transactions: 16, Accounts: 16, Heights: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	prevHash := ecommon.Hash{}
+	for h := uint64(0); h <= height; h++ {
+		block := &types.Block{ChainId: common.DummyChainId, Height: h, Timestamp: int64(h), PrevHash: prevHash}
+		block.Hash = block.GetHash()
+		if err = db.SetBlock(block, nil); err != nil {
+			t.Fatal(err)
+		}
+		prevHash = block.Hash
+	}
+
+	schema, err := newGraphqlSchema(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Server{graphqlSchema: schema, maxBodySize: 1 << 20}
+}
+
+func queryGraphql(t *testing.T, s *Server, query string) (data json.RawMessage, errs []string) {
+	t.Helper()
+	body, _ := json.Marshal(graphqlRequest{Query: query})
+	recorder := httptest.NewRecorder()
+	s.serveGraphql(recorder, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+
+	var response struct {
+		Data   json.RawMessage
+		Errors []struct{ Message string }
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range response.Errors {
+		errs = append(errs, e.Message)
+	}
+	return response.Data, errs
+}
+
+func TestGraphqlItemBudget(t *testing.T) {
+	s := newTestGraphqlServer(t, common.MaxBlocksInterval)
+
+	data, errs := queryGraphql(t, s, `{ blocks(fromHeight: 1) { height parent { height } } }`)
+	if len(errs) != 0 || len(data) == 0 {
+		t.Fatalf("a query within the budget must succeed, got %v", errs)
+	}
+
+	// Aliases repeat the largest list without adding depth
+	var query strings.Builder
+	query.WriteString("{")
+	for i := 0; i <= graphqlMaxItems/common.MaxBlocksInterval; i++ {
+		fmt.Fprintf(&query, " b%d: blocks(fromHeight: 1) { height }", i)
+	}
+	query.WriteString(" }")
+	data, errs = queryGraphql(t, s, query.String())
+	if len(errs) != 1 || errs[0] != errQueryTooExpensive.Error() {
+		t.Fatalf("expected the query to be rejected, got %v", errs)
+	}
+	if len(data) != 0 && string(data) != "null" {
+		t.Fatalf("a rejected query must not return data, got %s", data)
+	}
+}
+
+func TestGraphqlAccountTransactionsChargedUpFront(t *testing.T) {
+	s := newTestGraphqlServer(t, 1)
+	address := ecommon.Address{1}.String()
+
+	// The account has no transaction, the pages are charged before the storage is read
+	var query strings.Builder
+	query.WriteString("{")
+	for i := 0; i <= graphqlMaxItems/common.MaxAccountTransactions; i++ {
+		fmt.Fprintf(&query, ` t%d: transactions(address: "%s") { hash }`, i, address)
+	}
+	query.WriteString(" }")
+	if _, errs := queryGraphql(t, s, query.String()); len(errs) != 1 || errs[0] != errQueryTooExpensive.Error() {
+		t.Fatalf("expected the query to be rejected, got %v", errs)
+	}
+}
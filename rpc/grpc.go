@@ -0,0 +1,388 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"dummy-chain/common"
+	"dummy-chain/common/config"
+	"dummy-chain/common/types"
+	"dummy-chain/rpc/pb"
+	"dummy-chain/storage"
+	"encoding/base64"
+	"math/big"
+	"net"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// GrpcServer serves the Chain gRPC service, every call goes through the same Service as JSON-RPC
+type GrpcServer struct {
+	pb.UnimplementedChainServer
+
+	service  *Service
+	storage  *storage.BadgerDb
+	eventBus *EventBus
+	auth     *authenticator
+	limiter  *RateLimiter
+	server   *grpc.Server
+
+	// Cancelled by Stop, ends the block streams that would otherwise run until their client leaves
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewGrpcServer(db *storage.BadgerDb, memPool *MemoryPool, eventBus *EventBus, grpcConfig config.GrpcConfig, authConfig config.AuthConfig, limiter *RateLimiter) (*GrpcServer, error) {
+	auth, err := newAuthenticator(authConfig)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	g := &GrpcServer{
+		service:  NewService(db, memPool),
+		storage:  db,
+		eventBus: eventBus,
+		auth:     auth,
+		limiter:  limiter,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	options := []grpc.ServerOption{
+		grpc.UnaryInterceptor(g.authorizeUnary),
+		grpc.StreamInterceptor(g.authorizeStream),
+	}
+	if grpcConfig.TlsEnabled() {
+		certificate, errLoad := tls.LoadX509KeyPair(grpcConfig.TlsCertFile, grpcConfig.TlsKeyFile)
+		if errLoad != nil {
+			return nil, errLoad
+		}
+		tlsConfig := auth.tlsConfig()
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	g.server = grpc.NewServer(options...)
+	pb.RegisterChainServer(g.server, g)
+	return g, nil
+}
+
+// Start blocks serving on address until Stop is called
+func (g *GrpcServer) Start(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return g.server.Serve(listener)
+}
+
+// Stop ends the open block streams and waits for the running calls, they are cut when ctx is done
+func (g *GrpcServer) Stop(ctx context.Context) {
+	g.cancel()
+	stopped := make(chan struct{})
+	go func() {
+		g.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		g.server.Stop()
+	}
+}
+
+func (g *GrpcServer) GetAccountInfo(ctx context.Context, request *pb.GetAccountInfoRequest) (*pb.Account, error) {
+	address, err := grpcAddress(request.Address)
+	if err != nil {
+		return nil, err
+	}
+	var account types.AccountInfo
+	if err = g.service.GetAccountInfo(address, &account); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Account{
+		Address: ecommon.HexToAddress(account.Address).Bytes(),
+		Nonce:   account.Nonce,
+		Balance: account.BalanceRaw.String(),
+	}, nil
+}
+
+func (g *GrpcServer) GetTransactionByHash(ctx context.Context, request *pb.GetTransactionByHashRequest) (*pb.Transaction, error) {
+	hash, err := grpcHash(request.Hash)
+	if err != nil {
+		return nil, err
+	}
+	var tx types.TransactionInfo
+	if err = g.service.GetTransactionByHash(hash, &tx); err != nil {
+		return nil, toStatus(err)
+	}
+	return toPbTransaction(&tx), nil
+}
+
+func (g *GrpcServer) GetCurrentBlockHeight(ctx context.Context, request *pb.GetCurrentBlockHeightRequest) (*pb.GetCurrentBlockHeightResponse, error) {
+	var height uint64
+	if err := g.service.GetCurrenBlockHeight(nil, &height); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.GetCurrentBlockHeightResponse{Height: height}, nil
+}
+
+func (g *GrpcServer) GetBlockByHash(ctx context.Context, request *pb.GetBlockByHashRequest) (*pb.Block, error) {
+	hash, err := grpcHash(request.Hash)
+	if err != nil {
+		return nil, err
+	}
+	var block types.BlockInfo
+	if err = g.service.GetBlockByHash(hash, &block); err != nil {
+		return nil, toStatus(err)
+	}
+	return toPbBlock(&block), nil
+}
+
+func (g *GrpcServer) GetBlockByHeight(ctx context.Context, request *pb.GetBlockByHeightRequest) (*pb.Block, error) {
+	var block types.BlockInfo
+	if err := g.service.GetBlockByHeight(request.Height, &block); err != nil {
+		return nil, toStatus(err)
+	}
+	return toPbBlock(&block), nil
+}
+
+func (g *GrpcServer) GetBlocksInterval(ctx context.Context, request *pb.GetBlocksIntervalRequest) (*pb.GetBlocksIntervalResponse, error) {
+	var list types.BlockInfoList
+	if err := g.service.GetBlocksInterval(BlockInterval{Left: request.Left, Right: request.Right}, &list); err != nil {
+		return nil, toStatus(err)
+	}
+	response := &pb.GetBlocksIntervalResponse{Blocks: make([]*pb.Block, 0, len(list.Blocks))}
+	for i := range list.Blocks {
+		response.Blocks = append(response.Blocks, toPbBlock(&list.Blocks[i]))
+	}
+	return response, nil
+}
+
+func (g *GrpcServer) GetAccountTransactions(ctx context.Context, request *pb.GetAccountTransactionsRequest) (*pb.GetAccountTransactionsResponse, error) {
+	address, err := grpcAddress(request.Address)
+	if err != nil {
+		return nil, err
+	}
+	query := AccountTransactionsQuery{
+		Address: address,
+		Offset:  int(request.Offset),
+		Limit:   int(request.Limit),
+	}
+	var list types.TransactionInfoList
+	if err = g.service.GetAccountTransactions(query, &list); err != nil {
+		return nil, toStatus(err)
+	}
+	response := &pb.GetAccountTransactionsResponse{Transactions: make([]*pb.Transaction, 0, len(list.Transactions))}
+	for i := range list.Transactions {
+		response.Transactions = append(response.Transactions, toPbTransaction(&list.Transactions[i]))
+	}
+	return response, nil
+}
+
+func (g *GrpcServer) SendTransaction(ctx context.Context, request *pb.SendTransactionRequest) (*pb.SendTransactionResponse, error) {
+	tx, err := g.service.submitTransaction(base64.StdEncoding.EncodeToString(request.Transaction))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.SendTransactionResponse{Hash: tx.Hash.Bytes()}, nil
+}
+
+func (g *GrpcServer) authorizeUnary(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := g.authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, request)
+}
+
+func (g *GrpcServer) authorizeStream(server interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := g.authorize(stream.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(server, stream)
+}
+
+// authorize checks the bearer token of the authorization metadata and the client certificate against the permission
+// of the method and charges the call to the rate limit of the client, a block stream pays once when it opens
+func (g *GrpcServer) authorize(ctx context.Context, fullMethod string) error {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = bearerToken(values[0])
+		}
+	}
+	p, hasPeer := peer.FromContext(ctx)
+	var remoteAddr string
+	if hasPeer {
+		remoteAddr = p.Addr.String()
+	}
+	c, err := g.auth.authenticateToken(token, remoteAddr)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	if hasPeer {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			g.auth.authenticateClientCert(c, &info.State)
+		}
+	}
+
+	permission, cost := ReadPermission, cheapCall
+	switch fullMethod {
+	case pb.Chain_SendTransaction_FullMethodName:
+		permission, cost = SendPermission, expensiveCall
+	case pb.Chain_GetBlocksInterval_FullMethodName, pb.Chain_GetAccountTransactions_FullMethodName, pb.Chain_StreamBlocks_FullMethodName:
+		cost = expensiveCall
+	}
+	if err = c.check(permission, fullMethod); err != nil {
+		if !c.authenticated {
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	if _, err = g.limiter.allow(clientKey(c, remoteAddr), cost); err != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return nil
+}
+
+// StreamBlocks backfills from the requested height and then follows the tip
+// A stream dropped by the event bus for being too slow ends with Unavailable, the client resumes from its last height + 1
+func (g *GrpcServer) StreamBlocks(request *pb.StreamBlocksRequest, stream grpc.ServerStreamingServer[pb.Block]) error {
+	// Subscribe before the backfill so no block is lost in between
+	sub := g.eventBus.Subscribe()
+	defer g.eventBus.Unsubscribe(sub)
+
+	send := func(block *types.BlockInfo) error {
+		return stream.Send(toPbBlock(block))
+	}
+
+	height, err := g.storage.GetHeight()
+	if err != nil {
+		return toStatus(err)
+	}
+	next, err := backfillBlocks(g.storage, request.FromHeight, height, send)
+	if err != nil {
+		return toStatus(err)
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-g.ctx.Done():
+			return status.Errorf(codes.Unavailable, "server stopping, resume from height %d", next)
+		case event, ok := <-sub.C:
+			if !ok {
+				return status.Errorf(codes.Unavailable, "stream fell behind, resume from height %d", next)
+			}
+			if event.Kind != NewBlockEvent || event.Block.Height < next {
+				continue
+			}
+			if event.Block.Height > next {
+				if next, err = backfillBlocks(g.storage, next, event.Block.Height-1, send); err != nil {
+					return toStatus(err)
+				}
+			}
+			if err = send(event.Block); err != nil {
+				return err
+			}
+			next = event.Block.Height + 1
+		}
+	}
+}
+
+// backfillBlocks sends the stored blocks from next to last and returns the next height to send
+func backfillBlocks(db *storage.BadgerDb, next, last uint64, send func(block *types.BlockInfo) error) (uint64, error) {
+	for next <= last {
+		right := next + common.MaxBlocksInterval - 1
+		if right > last {
+			right = last
+		}
+		entries, err := db.GetBlockRange(next, right)
+		if err != nil {
+			return next, err
+		} else if len(entries) == 0 {
+			return next, nil
+		}
+		for _, entry := range entries {
+			blockInfo := entry.ToInfo()
+			if err = send(&blockInfo); err != nil {
+				return next, err
+			}
+			next = blockInfo.Height + 1
+		}
+	}
+	return next, nil
+}
+
+// toStatus maps a service error to a gRPC status, using the same classification as the JSON-RPC codes
+func toStatus(err error) error {
+	rpcErr := toError(err)
+	code := codes.Internal
+	switch rpcErr.Code {
+	case NotFoundCode:
+		code = codes.NotFound
+	case InvalidParamsCode, InvalidTransactionCode:
+		code = codes.InvalidArgument
+	case UnauthorizedCode:
+		code = codes.PermissionDenied
+	case RateLimitedCode:
+		code = codes.ResourceExhausted
+	}
+	return status.Error(code, rpcErr.Message)
+}
+
+func grpcAddress(data []byte) (ecommon.Address, error) {
+	if len(data) != ecommon.AddressLength {
+		return ecommon.Address{}, status.Errorf(codes.InvalidArgument, "address must be %d bytes", ecommon.AddressLength)
+	}
+	return ecommon.BytesToAddress(data), nil
+}
+
+func grpcHash(data []byte) (ecommon.Hash, error) {
+	if len(data) != ecommon.HashLength {
+		return ecommon.Hash{}, status.Errorf(codes.InvalidArgument, "hash must be %d bytes", ecommon.HashLength)
+	}
+	return ecommon.BytesToHash(data), nil
+}
+
+func toPbTransaction(tx *types.TransactionInfo) *pb.Transaction {
+	signature, _ := base64.StdEncoding.DecodeString(tx.Signature)
+	raw, _ := base64.StdEncoding.DecodeString(tx.Raw)
+	value := tx.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	return &pb.Transaction{
+		Hash:        ecommon.HexToHash(tx.Hash).Bytes(),
+		BlockHeight: tx.BlockHeight,
+		From:        ecommon.HexToAddress(tx.From).Bytes(),
+		Nonce:       tx.Nonce,
+		To:          ecommon.HexToAddress(tx.To).Bytes(),
+		Value:       value.String(),
+		Signature:   signature,
+		Raw:         raw,
+	}
+}
+
+func toPbBlock(block *types.BlockInfo) *pb.Block {
+	signature, _ := base64.StdEncoding.DecodeString(block.Signature)
+	result := &pb.Block{
+		ChainId:      block.ChainId,
+		Hash:         ecommon.HexToHash(block.Hash).Bytes(),
+		Height:       block.Height,
+		Timestamp:    block.Timestamp,
+		PrevHash:     ecommon.HexToHash(block.PrevHash).Bytes(),
+		Validator:    ecommon.HexToAddress(block.Validator).Bytes(),
+		Signature:    signature,
+		Transactions: make([]*pb.Transaction, 0, len(block.Transactions)),
+	}
+	for i := range block.Transactions {
+		result.Transactions = append(result.Transactions, toPbTransaction(&block.Transactions[i]))
+	}
+	return result
+}
@@ -0,0 +1,317 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Largest number of calls accepted in a single batch
+const maxBatchSize = 100
+
+var (
+	typeOfError       = reflect.TypeOf((*error)(nil)).Elem()
+	typeOfUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+type jsonRequest struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Id      json.RawMessage `json:"id,omitempty"`
+}
+
+type jsonResponse struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	Id      json.RawMessage `json:"id"`
+}
+
+// rpcMethod is a service method with the net/rpc signature: func (s *T) Method(arg A, reply *R) error
+type rpcMethod struct {
+	receiver  reflect.Value
+	function  reflect.Value
+	argType   reflect.Type
+	replyType reflect.Type
+}
+
+// registry dispatches JSON-RPC 2.0 calls to the methods of the registered services
+type registry struct {
+	methods map[string]*rpcMethod
+}
+
+func newRegistry() *registry {
+	return &registry{
+		methods: make(map[string]*rpcMethod),
+	}
+}
+
+// register exposes every method of receiver with the net/rpc signature as namespace.Method
+func (r *registry) register(namespace string, receiver interface{}) error {
+	return r.registerNamed(namespace, receiver, func(method string) string {
+		return namespace + "." + method
+	})
+}
+
+// registerEthStyle exposes the methods as namespace_method, the naming used by Ethereum clients
+func (r *registry) registerEthStyle(namespace string, receiver interface{}) error {
+	return r.registerNamed(namespace, receiver, func(method string) string {
+		return namespace + "_" + strings.ToLower(method[:1]) + method[1:]
+	})
+}
+
+func (r *registry) registerNamed(namespace string, receiver interface{}, name func(method string) string) error {
+	value := reflect.ValueOf(receiver)
+	t := value.Type()
+
+	count := 0
+	for i := 0; i < t.NumMethod(); i++ {
+		method := t.Method(i)
+		methodType := method.Type
+		if methodType.NumIn() != 3 || methodType.NumOut() != 1 || methodType.Out(0) != typeOfError {
+			continue
+		}
+		if methodType.In(2).Kind() != reflect.Ptr {
+			continue
+		}
+		r.methods[name(method.Name)] = &rpcMethod{
+			receiver:  value,
+			function:  method.Func,
+			argType:   methodType.In(1),
+			replyType: methodType.In(2).Elem(),
+		}
+		count += 1
+	}
+	if count == 0 {
+		return errors.Errorf("service %s has no suitable methods", namespace)
+	}
+	return nil
+}
+
+// handle processes a single call or a batch on behalf of c, charging q, and returns the encoded reply
+// A nil reply means that every call was a notification and nothing must be written back
+func (r *registry) handle(body []byte, c *caller, q *quota) []byte {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	if len(trimmed) == 0 {
+		return encodeResponse(errorResponse(nil, newError(ParseErrorCode, "empty request")))
+	}
+
+	if trimmed[0] != '[' {
+		var raw json.RawMessage
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return encodeResponse(errorResponse(nil, newError(ParseErrorCode, err.Error())))
+		}
+		if response := r.handleRequest(raw, c, q); response != nil {
+			return encodeResponse(response)
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(trimmed, &batch); err != nil {
+		return encodeResponse(errorResponse(nil, newError(ParseErrorCode, err.Error())))
+	}
+	if len(batch) == 0 {
+		return encodeResponse(errorResponse(nil, newError(InvalidRequestCode, "empty batch")))
+	}
+	if len(batch) > maxBatchSize {
+		return encodeResponse(errorResponse(nil, newError(InvalidRequestCode, fmt.Sprintf("batch is larger than %d calls", maxBatchSize))))
+	}
+
+	responses := make([]*jsonResponse, 0, len(batch))
+	for _, raw := range batch {
+		if response := r.handleRequest(raw, c, q); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+	return encodeResponse(responses)
+}
+
+// handleRequest runs one call, it returns nil for notifications
+func (r *registry) handleRequest(raw json.RawMessage, c *caller, q *quota) *jsonResponse {
+	var request jsonRequest
+	if err := json.Unmarshal(raw, &request); err != nil {
+		return errorResponse(nil, newError(InvalidRequestCode, err.Error()))
+	}
+	if request.JsonRpc != "2.0" || request.Method == "" || !validId(request.Id) {
+		return errorResponse(request.Id, newError(InvalidRequestCode, "invalid request"))
+	}
+	notification := request.Id == nil
+
+	result, rpcErr := r.call(request.Method, request.Params, c, q)
+	if notification {
+		return nil
+	}
+	if rpcErr != nil {
+		return errorResponse(request.Id, rpcErr)
+	}
+	return &jsonResponse{
+		JsonRpc: "2.0",
+		Result:  result,
+		Id:      request.Id,
+	}
+}
+
+func (r *registry) call(name string, params json.RawMessage, c *caller, q *quota) (result json.RawMessage, rpcErr *Error) {
+	method, ok := r.methods[name]
+	if !ok {
+		return nil, newError(MethodNotFoundCode, fmt.Sprintf("method %s not found", name))
+	}
+	if err := c.check(methodPermission(name), name); err != nil {
+		return nil, toError(err)
+	}
+	if err := q.allow(methodCost(name)); err != nil {
+		return nil, toError(err)
+	}
+
+	arg, err := method.decodeParams(params)
+	if err != nil {
+		return nil, newError(InvalidParamsCode, err.Error())
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result, rpcErr = nil, newError(InternalErrorCode, fmt.Sprintf("%v", recovered))
+		}
+	}()
+
+	reply := reflect.New(method.replyType)
+	out := method.function.Call([]reflect.Value{method.receiver, arg, reply})
+	if errCall := out[0].Interface(); errCall != nil {
+		return nil, toError(errCall.(error))
+	}
+
+	result, err = json.Marshal(reply.Interface())
+	if err != nil {
+		return nil, newError(InternalErrorCode, err.Error())
+	}
+	return result, nil
+}
+
+// decodeParams accepts
+//   - nothing or null for methods that don't need an argument
+//   - a positional array, [x] is the argument itself and [a, b] fills the fields of a struct argument in order
+//     trailing fields may be left out
+//   - a named object, which fills a struct argument or, with a single member, is the argument itself
+func (m *rpcMethod) decodeParams(params json.RawMessage) (reflect.Value, error) {
+	isPtr := m.argType.Kind() == reflect.Ptr
+	base := m.argType
+	if isPtr {
+		base = base.Elem()
+	}
+	arg := reflect.New(base)
+	// Structs with their own JSON decoding are plain values, not a set of params
+	isStruct := base.Kind() == reflect.Struct && !reflect.PointerTo(base).Implements(typeOfUnmarshaler)
+
+	trimmed := bytes.TrimSpace(params)
+	switch {
+	case len(trimmed) == 0 || string(trimmed) == "null":
+	case trimmed[0] == '[':
+		var list []json.RawMessage
+		if err := json.Unmarshal(trimmed, &list); err != nil {
+			return reflect.Value{}, err
+		}
+		// A lone object or null still fills a struct argument by name, anything else is positional for structs
+		if len(list) == 1 && (!isStruct || isObjectOrNull(list[0])) {
+			if err := json.Unmarshal(list[0], arg.Interface()); err != nil {
+				return reflect.Value{}, err
+			}
+		} else if len(list) > 0 {
+			if err := decodePositional(arg.Elem(), list); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+	case trimmed[0] == '{':
+		if isStruct {
+			if err := json.Unmarshal(trimmed, arg.Interface()); err != nil {
+				return reflect.Value{}, err
+			}
+			break
+		}
+		var named map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &named); err != nil {
+			return reflect.Value{}, err
+		}
+		if len(named) != 1 {
+			return reflect.Value{}, errors.New("expected a single named param")
+		}
+		for _, value := range named {
+			if err := json.Unmarshal(value, arg.Interface()); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+	default:
+		return reflect.Value{}, errors.New("params must be an array or an object")
+	}
+
+	if isPtr {
+		return arg, nil
+	}
+	return arg.Elem(), nil
+}
+
+func decodePositional(arg reflect.Value, list []json.RawMessage) error {
+	if arg.Kind() != reflect.Struct {
+		return errors.Errorf("expected a single param, got %d", len(list))
+	}
+	fields := make([]reflect.Value, 0, arg.NumField())
+	for i := 0; i < arg.NumField(); i++ {
+		if arg.Type().Field(i).IsExported() {
+			fields = append(fields, arg.Field(i))
+		}
+	}
+	if len(list) > len(fields) {
+		return errors.Errorf("expected at most %d params, got %d", len(fields), len(list))
+	}
+	for i, value := range list {
+		if err := json.Unmarshal(value, fields[i].Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isObjectOrNull(value json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(value)
+	return bytes.HasPrefix(trimmed, []byte("{")) || string(trimmed) == "null"
+}
+
+// validId accepts a missing id (notification), a string, a number or null
+func validId(id json.RawMessage) bool {
+	if id == nil {
+		return true
+	}
+	trimmed := strings.TrimSpace(string(id))
+	if trimmed == "null" || strings.HasPrefix(trimmed, "\"") {
+		return true
+	}
+	var number json.Number
+	return json.Unmarshal(id, &number) == nil
+}
+
+func errorResponse(id json.RawMessage, rpcErr *Error) *jsonResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &jsonResponse{
+		JsonRpc: "2.0",
+		Error:   rpcErr,
+		Id:      id,
+	}
+}
+
+func encodeResponse(response interface{}) []byte {
+	data, err := json.Marshal(response)
+	if err != nil {
+		data, _ = json.Marshal(errorResponse(nil, newError(InternalErrorCode, err.Error())))
+	}
+	return data
+}
@@ -2,27 +2,67 @@ package rpc
 
 import (
 	"dummy-chain/common/types"
+	"fmt"
 	"sync"
 
 	ecommon "github.com/ethereum/go-ethereum/common"
 )
 
+const (
+	// Pending transactions kept at most, and per sender, so peers and callers can't grow the pool without bound
+	maxMemPoolSize      = 10000
+	maxPendingPerSender = 64
+)
+
 type MemoryPool struct {
-	memPool map[ecommon.Hash]*types.Transaction
-	lock    sync.Mutex
+	memPool  map[ecommon.Hash]*types.Transaction
+	lock     sync.Mutex
+	eventBus *EventBus
 }
 
-func NewMemoryPool() *MemoryPool {
+func NewMemoryPool(eventBus *EventBus) *MemoryPool {
 	return &MemoryPool{
-		memPool: make(map[ecommon.Hash]*types.Transaction),
-		lock:    sync.Mutex{},
+		memPool:  make(map[ecommon.Hash]*types.Transaction),
+		lock:     sync.Mutex{},
+		eventBus: eventBus,
 	}
 }
 
-func (mp *MemoryPool) AddTransaction(tx *types.Transaction) {
+// AddTransaction stores the transaction and announces it as pending the first time it is seen
+// A new transaction is refused with ErrMemPoolFull when the pool or its sender's share is full
+func (mp *MemoryPool) AddTransaction(tx *types.Transaction) error {
 	mp.lock.Lock()
+	_, known := mp.memPool[tx.Hash]
+	if !known {
+		if err := mp.checkCapacity(tx.From); err != nil {
+			mp.lock.Unlock()
+			return err
+		}
+	}
 	mp.memPool[tx.Hash] = tx
 	mp.lock.Unlock()
+
+	if !known && mp.eventBus != nil {
+		mp.eventBus.PublishTransaction(tx.ToInfo())
+	}
+	return nil
+}
+
+// checkCapacity tells whether one more transaction of sender fits, the lock must be held
+func (mp *MemoryPool) checkCapacity(sender ecommon.Address) error {
+	if len(mp.memPool) >= maxMemPoolSize {
+		return fmt.Errorf("%w: %d pending transactions", ErrMemPoolFull, len(mp.memPool))
+	}
+	pending := 0
+	for _, tx := range mp.memPool {
+		if tx.From == sender {
+			pending += 1
+		}
+	}
+	if pending >= maxPendingPerSender {
+		return fmt.Errorf("%w: %s has %d pending transactions", ErrMemPoolFull, sender, pending)
+	}
+	return nil
 }
 
 func (mp *MemoryPool) GetMemPool() []*types.Transaction {
@@ -42,3 +82,27 @@ func (mp *MemoryPool) RemoveTxs(tx []*types.Transaction) {
 	}
 	mp.lock.Unlock()
 }
+
+func (mp *MemoryPool) GetTransaction(hash ecommon.Hash) (*types.Transaction, bool) {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+	tx, ok := mp.memPool[hash]
+	return tx, ok
+}
+
+// PendingNonce returns the next nonce of address once every consecutive pending transaction starting at nonce is included
+func (mp *MemoryPool) PendingNonce(address ecommon.Address, nonce uint64) uint64 {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+
+	used := make(map[uint64]bool)
+	for _, tx := range mp.memPool {
+		if tx.From == address {
+			used[tx.Nonce] = true
+		}
+	}
+	for used[nonce] {
+		nonce += 1
+	}
+	return nonce
+}
@@ -0,0 +1,51 @@
+package rpc
+
+import (
+	"dummy-chain/common/types"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+)
+
+func testPendingTx(from ecommon.Address, nonce uint64) *types.Transaction {
+	var hash ecommon.Hash
+	copy(hash[:], from[:])
+	binary.BigEndian.PutUint64(hash[24:], nonce)
+	return &types.Transaction{Hash: hash, From: from, Nonce: nonce}
+}
+
+func TestMemPoolPerSenderLimit(t *testing.T) {
+	mp := NewMemoryPool(nil)
+	sender := ecommon.Address{1}
+	for nonce := uint64(0); nonce < maxPendingPerSender; nonce++ {
+		if err := mp.AddTransaction(testPendingTx(sender, nonce)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mp.AddTransaction(testPendingTx(sender, maxPendingPerSender)); !errors.Is(err, ErrMemPoolFull) {
+		t.Fatalf("the sender's share must be full, got %v", err)
+	}
+	// Resubmitting a pending transaction still succeeds
+	if err := mp.AddTransaction(testPendingTx(sender, 0)); err != nil {
+		t.Fatalf("a pending transaction must be accepted again: %v", err)
+	}
+	if err := mp.AddTransaction(testPendingTx(ecommon.Address{2}, 0)); err != nil {
+		t.Fatalf("other senders must not be limited: %v", err)
+	}
+}
+
+func TestMemPoolSizeLimit(t *testing.T) {
+	mp := NewMemoryPool(nil)
+	for i := 0; i < maxMemPoolSize; i++ {
+		var sender ecommon.Address
+		binary.BigEndian.PutUint64(sender[12:], uint64(i))
+		if err := mp.AddTransaction(testPendingTx(sender, 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mp.AddTransaction(testPendingTx(ecommon.Address{0xff}, 0)); !errors.Is(err, ErrMemPoolFull) {
+		t.Fatalf("the pool must be full, got %v", err)
+	}
+}
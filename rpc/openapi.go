@@ -0,0 +1,173 @@
+package rpc
+
+import (
+	"dummy-chain/metadata"
+	"encoding"
+	"math/big"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	typeOfBigInt        = reflect.TypeOf(big.Int{})
+	typeOfTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// openApiDocument builds the OpenAPI 3 description of the REST routes
+// Schemas are generated from the Go types, so the document can't drift from what the handlers return
+func openApiDocument(routes []restRoute) map[string]interface{} {
+	schemas := make(map[string]interface{})
+	errorSchema := schemaOf(reflect.TypeOf(RestError{}), schemas)
+
+	paths := make(map[string]interface{})
+	for _, route := range routes {
+		parameters := make([]interface{}, 0, len(route.params))
+		for _, param := range route.params {
+			parameters = append(parameters, map[string]interface{}{
+				"name":        param.name,
+				"in":          param.in,
+				"description": param.description,
+				"required":    param.required,
+				"schema":      map[string]interface{}{"type": param.schemaType},
+			})
+		}
+
+		responses := map[string]interface{}{
+			strconv.Itoa(route.status): map[string]interface{}{
+				"description": http.StatusText(route.status),
+				"content":     jsonContent(schemaOf(reflect.TypeOf(route.response), schemas)),
+			},
+			"default": map[string]interface{}{
+				"description": "JSON-RPC error object, 400 for invalid input, 404 when not found",
+				"content":     jsonContent(errorSchema),
+			},
+		}
+
+		operation := map[string]interface{}{
+			"operationId": route.operationId,
+			"summary":     route.summary,
+			"parameters":  parameters,
+			"responses":   responses,
+		}
+		if route.request != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  jsonContent(schemaOf(reflect.TypeOf(route.request), schemas)),
+			}
+		}
+
+		item, ok := paths[route.path].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[route.path] = item
+		}
+		item[strings.ToLower(route.method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Dummy chain REST API",
+			"version": metadata.Version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}
+}
+
+// schemaOf describes how encoding/json renders the type, named structs are added to schemas and referenced
+func schemaOf(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == typeOfBigInt:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.PointerTo(t).Implements(typeOfTextMarshaler):
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOf(t.Elem(), schemas)}
+	case reflect.Struct:
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+		if _, ok := schemas[t.Name()]; ok {
+			return ref
+		}
+		// Reserve the name first so recursive types terminate
+		schemas[t.Name()] = nil
+
+		properties := make(map[string]interface{})
+		required := make([]string, 0)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, omitEmpty := jsonFieldName(field)
+			if name == "-" {
+				continue
+			}
+			properties[name] = schemaOf(field.Type, schemas)
+			if !omitEmpty {
+				required = append(required, name)
+			}
+		}
+		schema := map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		schemas[t.Name()] = schema
+		return ref
+	}
+	// interface{} can hold anything
+	return map[string]interface{}{}
+}
+
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	omitEmpty := false
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{
+			"schema": schema,
+		},
+	}
+}
@@ -0,0 +1,1023 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: rpc/pb/chain.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Account struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       []byte                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Nonce         uint64                 `protobuf:"varint,2,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Balance       string                 `protobuf:"bytes,3,opt,name=balance,proto3" json:"balance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Account) Reset() {
+	*x = Account{}
+	mi := &file_rpc_pb_chain_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Account) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Account) ProtoMessage() {}
+
+func (x *Account) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_chain_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Account.ProtoReflect.Descriptor instead.
+func (*Account) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_chain_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Account) GetAddress() []byte {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+func (x *Account) GetNonce() uint64 {
+	if x != nil {
+		return x.Nonce
+	}
+	return 0
+}
+
+func (x *Account) GetBalance() string {
+	if x != nil {
+		return x.Balance
+	}
+	return ""
+}
+
+type Transaction struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Hash        []byte                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	BlockHeight uint64                 `protobuf:"varint,2,opt,name=block_height,json=blockHeight,proto3" json:"block_height,omitempty"`
+	From        []byte                 `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	Nonce       uint64                 `protobuf:"varint,4,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	To          []byte                 `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`
+	Value       string                 `protobuf:"bytes,6,opt,name=value,proto3" json:"value,omitempty"`
+	Signature   []byte                 `protobuf:"bytes,7,opt,name=signature,proto3" json:"signature,omitempty"`
+	// RLP encoding for transactions received through eth_sendRawTransaction
+	Raw           []byte `protobuf:"bytes,8,opt,name=raw,proto3" json:"raw,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	mi := &file_rpc_pb_chain_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_chain_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_chain_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Transaction) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *Transaction) GetBlockHeight() uint64 {
+	if x != nil {
+		return x.BlockHeight
+	}
+	return 0
+}
+
+func (x *Transaction) GetFrom() []byte {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *Transaction) GetNonce() uint64 {
+	if x != nil {
+		return x.Nonce
+	}
+	return 0
+}
+
+func (x *Transaction) GetTo() []byte {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *Transaction) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Transaction) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *Transaction) GetRaw() []byte {
+	if x != nil {
+		return x.Raw
+	}
+	return nil
+}
+
+type Block struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainId       uint64                 `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Hash          []byte                 `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	Height        uint64                 `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	PrevHash      []byte                 `protobuf:"bytes,5,opt,name=prev_hash,json=prevHash,proto3" json:"prev_hash,omitempty"`
+	Validator     []byte                 `protobuf:"bytes,6,opt,name=validator,proto3" json:"validator,omitempty"`
+	Signature     []byte                 `protobuf:"bytes,7,opt,name=signature,proto3" json:"signature,omitempty"`
+	Transactions  []*Transaction         `protobuf:"bytes,8,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Block) Reset() {
+	*x = Block{}
+	mi := &file_rpc_pb_chain_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Block) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Block) ProtoMessage() {}
+
+func (x *Block) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_chain_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Block.ProtoReflect.Descriptor instead.
+func (*Block) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_chain_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Block) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *Block) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *Block) GetHeight() uint64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Block) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Block) GetPrevHash() []byte {
+	if x != nil {
+		return x.PrevHash
+	}
+	return nil
+}
+
+func (x *Block) GetValidator() []byte {
+	if x != nil {
+		return x.Validator
+	}
+	return nil
+}
+
+func (x *Block) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *Block) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+type GetAccountInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       []byte                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountInfoRequest) Reset() {
+	*x = GetAccountInfoRequest{}
+	mi := &file_rpc_pb_chain_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountInfoRequest) ProtoMessage() {}
+
+func (x *GetAccountInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_chain_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetAccountInfoRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_chain_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetAccountInfoRequest) GetAddress() []byte {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+type GetTransactionByHashRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          []byte                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionByHashRequest) Reset() {
+	*x = GetTransactionByHashRequest{}
+	mi := &file_rpc_pb_chain_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionByHashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionByHashRequest) ProtoMessage() {}
+
+func (x *GetTransactionByHashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_chain_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionByHashRequest.ProtoReflect.Descriptor instead.
+func (*GetTransactionByHashRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_chain_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetTransactionByHashRequest) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+type GetCurrentBlockHeightRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCurrentBlockHeightRequest) Reset() {
+	*x = GetCurrentBlockHeightRequest{}
+	mi := &file_rpc_pb_chain_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCurrentBlockHeightRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCurrentBlockHeightRequest) ProtoMessage() {}
+
+func (x *GetCurrentBlockHeightRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_chain_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCurrentBlockHeightRequest.ProtoReflect.Descriptor instead.
+func (*GetCurrentBlockHeightRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_chain_proto_rawDescGZIP(), []int{5}
+}
+
+type GetCurrentBlockHeightResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Height        uint64                 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCurrentBlockHeightResponse) Reset() {
+	*x = GetCurrentBlockHeightResponse{}
+	mi := &file_rpc_pb_chain_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCurrentBlockHeightResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCurrentBlockHeightResponse) ProtoMessage() {}
+
+func (x *GetCurrentBlockHeightResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_chain_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCurrentBlockHeightResponse.ProtoReflect.Descriptor instead.
+func (*GetCurrentBlockHeightResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_chain_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetCurrentBlockHeightResponse) GetHeight() uint64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+type GetBlockByHashRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          []byte                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlockByHashRequest) Reset() {
+	*x = GetBlockByHashRequest{}
+	mi := &file_rpc_pb_chain_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlockByHashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlockByHashRequest) ProtoMessage() {}
+
+func (x *GetBlockByHashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_chain_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlockByHashRequest.ProtoReflect.Descriptor instead.
+func (*GetBlockByHashRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_chain_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetBlockByHashRequest) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+type GetBlockByHeightRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Height        uint64                 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlockByHeightRequest) Reset() {
+	*x = GetBlockByHeightRequest{}
+	mi := &file_rpc_pb_chain_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlockByHeightRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlockByHeightRequest) ProtoMessage() {}
+
+func (x *GetBlockByHeightRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_chain_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlockByHeightRequest.ProtoReflect.Descriptor instead.
+func (*GetBlockByHeightRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_chain_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetBlockByHeightRequest) GetHeight() uint64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+type GetBlocksIntervalRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Left          uint64                 `protobuf:"varint,1,opt,name=left,proto3" json:"left,omitempty"`
+	Right         uint64                 `protobuf:"varint,2,opt,name=right,proto3" json:"right,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlocksIntervalRequest) Reset() {
+	*x = GetBlocksIntervalRequest{}
+	mi := &file_rpc_pb_chain_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlocksIntervalRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlocksIntervalRequest) ProtoMessage() {}
+
+func (x *GetBlocksIntervalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_chain_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlocksIntervalRequest.ProtoReflect.Descriptor instead.
+func (*GetBlocksIntervalRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_chain_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetBlocksIntervalRequest) GetLeft() uint64 {
+	if x != nil {
+		return x.Left
+	}
+	return 0
+}
+
+func (x *GetBlocksIntervalRequest) GetRight() uint64 {
+	if x != nil {
+		return x.Right
+	}
+	return 0
+}
+
+type GetBlocksIntervalResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Blocks        []*Block               `protobuf:"bytes,1,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlocksIntervalResponse) Reset() {
+	*x = GetBlocksIntervalResponse{}
+	mi := &file_rpc_pb_chain_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlocksIntervalResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlocksIntervalResponse) ProtoMessage() {}
+
+func (x *GetBlocksIntervalResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_chain_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlocksIntervalResponse.ProtoReflect.Descriptor instead.
+func (*GetBlocksIntervalResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_chain_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetBlocksIntervalResponse) GetBlocks() []*Block {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+type GetAccountTransactionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       []byte                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Offset        uint32                 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Limit         uint32                 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountTransactionsRequest) Reset() {
+	*x = GetAccountTransactionsRequest{}
+	mi := &file_rpc_pb_chain_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountTransactionsRequest) ProtoMessage() {}
+
+func (x *GetAccountTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_chain_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*GetAccountTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_chain_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetAccountTransactionsRequest) GetAddress() []byte {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+func (x *GetAccountTransactionsRequest) GetOffset() uint32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *GetAccountTransactionsRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetAccountTransactionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transactions  []*Transaction         `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountTransactionsResponse) Reset() {
+	*x = GetAccountTransactionsResponse{}
+	mi := &file_rpc_pb_chain_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountTransactionsResponse) ProtoMessage() {}
+
+func (x *GetAccountTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_chain_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*GetAccountTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_chain_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetAccountTransactionsResponse) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+type SendTransactionRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Gob encoded signed transaction, the same payload as chain.SendTransaction before base64
+	Transaction   []byte `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendTransactionRequest) Reset() {
+	*x = SendTransactionRequest{}
+	mi := &file_rpc_pb_chain_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendTransactionRequest) ProtoMessage() {}
+
+func (x *SendTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_chain_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendTransactionRequest.ProtoReflect.Descriptor instead.
+func (*SendTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_chain_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SendTransactionRequest) GetTransaction() []byte {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+type SendTransactionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          []byte                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendTransactionResponse) Reset() {
+	*x = SendTransactionResponse{}
+	mi := &file_rpc_pb_chain_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendTransactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendTransactionResponse) ProtoMessage() {}
+
+func (x *SendTransactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_chain_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendTransactionResponse.ProtoReflect.Descriptor instead.
+func (*SendTransactionResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_chain_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SendTransactionResponse) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+type StreamBlocksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromHeight    uint64                 `protobuf:"varint,1,opt,name=from_height,json=fromHeight,proto3" json:"from_height,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamBlocksRequest) Reset() {
+	*x = StreamBlocksRequest{}
+	mi := &file_rpc_pb_chain_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamBlocksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamBlocksRequest) ProtoMessage() {}
+
+func (x *StreamBlocksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_chain_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamBlocksRequest.ProtoReflect.Descriptor instead.
+func (*StreamBlocksRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_chain_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StreamBlocksRequest) GetFromHeight() uint64 {
+	if x != nil {
+		return x.FromHeight
+	}
+	return 0
+}
+
+var File_rpc_pb_chain_proto protoreflect.FileDescriptor
+
+const file_rpc_pb_chain_proto_rawDesc = "" +
+	"\n" +
+	"\x12rpc/pb/chain.proto\x12\rdummychain.v1\"S\n" +
+	"\aAccount\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\fR\aaddress\x12\x14\n" +
+	"\x05nonce\x18\x02 \x01(\x04R\x05nonce\x12\x18\n" +
+	"\abalance\x18\x03 \x01(\tR\abalance\"\xc4\x01\n" +
+	"\vTransaction\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\fR\x04hash\x12!\n" +
+	"\fblock_height\x18\x02 \x01(\x04R\vblockHeight\x12\x12\n" +
+	"\x04from\x18\x03 \x01(\fR\x04from\x12\x14\n" +
+	"\x05nonce\x18\x04 \x01(\x04R\x05nonce\x12\x0e\n" +
+	"\x02to\x18\x05 \x01(\fR\x02to\x12\x14\n" +
+	"\x05value\x18\x06 \x01(\tR\x05value\x12\x1c\n" +
+	"\tsignature\x18\a \x01(\fR\tsignature\x12\x10\n" +
+	"\x03raw\x18\b \x01(\fR\x03raw\"\x85\x02\n" +
+	"\x05Block\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x04R\achainId\x12\x12\n" +
+	"\x04hash\x18\x02 \x01(\fR\x04hash\x12\x16\n" +
+	"\x06height\x18\x03 \x01(\x04R\x06height\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\x12\x1b\n" +
+	"\tprev_hash\x18\x05 \x01(\fR\bprevHash\x12\x1c\n" +
+	"\tvalidator\x18\x06 \x01(\fR\tvalidator\x12\x1c\n" +
+	"\tsignature\x18\a \x01(\fR\tsignature\x12>\n" +
+	"\ftransactions\x18\b \x03(\v2\x1a.dummychain.v1.TransactionR\ftransactions\"1\n" +
+	"\x15GetAccountInfoRequest\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\fR\aaddress\"1\n" +
+	"\x1bGetTransactionByHashRequest\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\fR\x04hash\"\x1e\n" +
+	"\x1cGetCurrentBlockHeightRequest\"7\n" +
+	"\x1dGetCurrentBlockHeightResponse\x12\x16\n" +
+	"\x06height\x18\x01 \x01(\x04R\x06height\"+\n" +
+	"\x15GetBlockByHashRequest\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\fR\x04hash\"1\n" +
+	"\x17GetBlockByHeightRequest\x12\x16\n" +
+	"\x06height\x18\x01 \x01(\x04R\x06height\"D\n" +
+	"\x18GetBlocksIntervalRequest\x12\x12\n" +
+	"\x04left\x18\x01 \x01(\x04R\x04left\x12\x14\n" +
+	"\x05right\x18\x02 \x01(\x04R\x05right\"I\n" +
+	"\x19GetBlocksIntervalResponse\x12,\n" +
+	"\x06blocks\x18\x01 \x03(\v2\x14.dummychain.v1.BlockR\x06blocks\"g\n" +
+	"\x1dGetAccountTransactionsRequest\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\fR\aaddress\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\rR\x06offset\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\rR\x05limit\"`\n" +
+	"\x1eGetAccountTransactionsResponse\x12>\n" +
+	"\ftransactions\x18\x01 \x03(\v2\x1a.dummychain.v1.TransactionR\ftransactions\":\n" +
+	"\x16SendTransactionRequest\x12 \n" +
+	"\vtransaction\x18\x01 \x01(\fR\vtransaction\"-\n" +
+	"\x17SendTransactionResponse\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\fR\x04hash\"6\n" +
+	"\x13StreamBlocksRequest\x12\x1f\n" +
+	"\vfrom_height\x18\x01 \x01(\x04R\n" +
+	"fromHeight2\xd8\x06\n" +
+	"\x05Chain\x12N\n" +
+	"\x0eGetAccountInfo\x12$.dummychain.v1.GetAccountInfoRequest\x1a\x16.dummychain.v1.Account\x12^\n" +
+	"\x14GetTransactionByHash\x12*.dummychain.v1.GetTransactionByHashRequest\x1a\x1a.dummychain.v1.Transaction\x12r\n" +
+	"\x15GetCurrentBlockHeight\x12+.dummychain.v1.GetCurrentBlockHeightRequest\x1a,.dummychain.v1.GetCurrentBlockHeightResponse\x12L\n" +
+	"\x0eGetBlockByHash\x12$.dummychain.v1.GetBlockByHashRequest\x1a\x14.dummychain.v1.Block\x12P\n" +
+	"\x10GetBlockByHeight\x12&.dummychain.v1.GetBlockByHeightRequest\x1a\x14.dummychain.v1.Block\x12f\n" +
+	"\x11GetBlocksInterval\x12'.dummychain.v1.GetBlocksIntervalRequest\x1a(.dummychain.v1.GetBlocksIntervalResponse\x12u\n" +
+	"\x16GetAccountTransactions\x12,.dummychain.v1.GetAccountTransactionsRequest\x1a-.dummychain.v1.GetAccountTransactionsResponse\x12`\n" +
+	"\x0fSendTransaction\x12%.dummychain.v1.SendTransactionRequest\x1a&.dummychain.v1.SendTransactionResponse\x12J\n" +
+	"\fStreamBlocks\x12\".dummychain.v1.StreamBlocksRequest\x1a\x14.dummychain.v1.Block0\x01B\x17Z\x15dummy-chain/rpc/pb;pbb\x06proto3"
+
+var (
+	file_rpc_pb_chain_proto_rawDescOnce sync.Once
+	file_rpc_pb_chain_proto_rawDescData []byte
+)
+
+func file_rpc_pb_chain_proto_rawDescGZIP() []byte {
+	file_rpc_pb_chain_proto_rawDescOnce.Do(func() {
+		file_rpc_pb_chain_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rpc_pb_chain_proto_rawDesc), len(file_rpc_pb_chain_proto_rawDesc)))
+	})
+	return file_rpc_pb_chain_proto_rawDescData
+}
+
+var file_rpc_pb_chain_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_rpc_pb_chain_proto_goTypes = []any{
+	(*Account)(nil),                        // 0: dummychain.v1.Account
+	(*Transaction)(nil),                    // 1: dummychain.v1.Transaction
+	(*Block)(nil),                          // 2: dummychain.v1.Block
+	(*GetAccountInfoRequest)(nil),          // 3: dummychain.v1.GetAccountInfoRequest
+	(*GetTransactionByHashRequest)(nil),    // 4: dummychain.v1.GetTransactionByHashRequest
+	(*GetCurrentBlockHeightRequest)(nil),   // 5: dummychain.v1.GetCurrentBlockHeightRequest
+	(*GetCurrentBlockHeightResponse)(nil),  // 6: dummychain.v1.GetCurrentBlockHeightResponse
+	(*GetBlockByHashRequest)(nil),          // 7: dummychain.v1.GetBlockByHashRequest
+	(*GetBlockByHeightRequest)(nil),        // 8: dummychain.v1.GetBlockByHeightRequest
+	(*GetBlocksIntervalRequest)(nil),       // 9: dummychain.v1.GetBlocksIntervalRequest
+	(*GetBlocksIntervalResponse)(nil),      // 10: dummychain.v1.GetBlocksIntervalResponse
+	(*GetAccountTransactionsRequest)(nil),  // 11: dummychain.v1.GetAccountTransactionsRequest
+	(*GetAccountTransactionsResponse)(nil), // 12: dummychain.v1.GetAccountTransactionsResponse
+	(*SendTransactionRequest)(nil),         // 13: dummychain.v1.SendTransactionRequest
+	(*SendTransactionResponse)(nil),        // 14: dummychain.v1.SendTransactionResponse
+	(*StreamBlocksRequest)(nil),            // 15: dummychain.v1.StreamBlocksRequest
+}
+var file_rpc_pb_chain_proto_depIdxs = []int32{
+	1,  // 0: dummychain.v1.Block.transactions:type_name -> dummychain.v1.Transaction
+	2,  // 1: dummychain.v1.GetBlocksIntervalResponse.blocks:type_name -> dummychain.v1.Block
+	1,  // 2: dummychain.v1.GetAccountTransactionsResponse.transactions:type_name -> dummychain.v1.Transaction
+	3,  // 3: dummychain.v1.Chain.GetAccountInfo:input_type -> dummychain.v1.GetAccountInfoRequest
+	4,  // 4: dummychain.v1.Chain.GetTransactionByHash:input_type -> dummychain.v1.GetTransactionByHashRequest
+	5,  // 5: dummychain.v1.Chain.GetCurrentBlockHeight:input_type -> dummychain.v1.GetCurrentBlockHeightRequest
+	7,  // 6: dummychain.v1.Chain.GetBlockByHash:input_type -> dummychain.v1.GetBlockByHashRequest
+	8,  // 7: dummychain.v1.Chain.GetBlockByHeight:input_type -> dummychain.v1.GetBlockByHeightRequest
+	9,  // 8: dummychain.v1.Chain.GetBlocksInterval:input_type -> dummychain.v1.GetBlocksIntervalRequest
+	11, // 9: dummychain.v1.Chain.GetAccountTransactions:input_type -> dummychain.v1.GetAccountTransactionsRequest
+	13, // 10: dummychain.v1.Chain.SendTransaction:input_type -> dummychain.v1.SendTransactionRequest
+	15, // 11: dummychain.v1.Chain.StreamBlocks:input_type -> dummychain.v1.StreamBlocksRequest
+	0,  // 12: dummychain.v1.Chain.GetAccountInfo:output_type -> dummychain.v1.Account
+	1,  // 13: dummychain.v1.Chain.GetTransactionByHash:output_type -> dummychain.v1.Transaction
+	6,  // 14: dummychain.v1.Chain.GetCurrentBlockHeight:output_type -> dummychain.v1.GetCurrentBlockHeightResponse
+	2,  // 15: dummychain.v1.Chain.GetBlockByHash:output_type -> dummychain.v1.Block
+	2,  // 16: dummychain.v1.Chain.GetBlockByHeight:output_type -> dummychain.v1.Block
+	10, // 17: dummychain.v1.Chain.GetBlocksInterval:output_type -> dummychain.v1.GetBlocksIntervalResponse
+	12, // 18: dummychain.v1.Chain.GetAccountTransactions:output_type -> dummychain.v1.GetAccountTransactionsResponse
+	14, // 19: dummychain.v1.Chain.SendTransaction:output_type -> dummychain.v1.SendTransactionResponse
+	2,  // 20: dummychain.v1.Chain.StreamBlocks:output_type -> dummychain.v1.Block
+	12, // [12:21] is the sub-list for method output_type
+	3,  // [3:12] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_rpc_pb_chain_proto_init() }
+func file_rpc_pb_chain_proto_init() {
+	if File_rpc_pb_chain_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rpc_pb_chain_proto_rawDesc), len(file_rpc_pb_chain_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_rpc_pb_chain_proto_goTypes,
+		DependencyIndexes: file_rpc_pb_chain_proto_depIdxs,
+		MessageInfos:      file_rpc_pb_chain_proto_msgTypes,
+	}.Build()
+	File_rpc_pb_chain_proto = out.File
+	file_rpc_pb_chain_proto_goTypes = nil
+	file_rpc_pb_chain_proto_depIdxs = nil
+}
@@ -0,0 +1,246 @@
+package rpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"dummy-chain/common/config"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type callCost int
+
+const (
+	cheapCall callCost = iota
+	expensiveCall
+)
+
+// Calls outside of these tables are cheap reads
+var (
+	expensiveMethods = map[string]bool{
+		"chain.GetBlocksInterval":      true,
+		"chain.GetHeadersInterval":     true,
+		"chain.GetAccountTransactions": true,
+		"chain.SendTransaction":        true,
+		"eth_sendRawTransaction":       true,
+	}
+	expensiveNamespaces = []string{"admin."}
+)
+
+// methodCost is the bucket a JSON-RPC method draws from
+func methodCost(method string) callCost {
+	for _, prefix := range expensiveNamespaces {
+		if strings.HasPrefix(method, prefix) {
+			return expensiveCall
+		}
+	}
+	if expensiveMethods[method] {
+		return expensiveCall
+	}
+	return cheapCall
+}
+
+// tokenBucket refills rate tokens per second up to burst, a call takes one token
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take returns 0 when a token was taken, otherwise how long until the next one is available
+func (b *tokenBucket) take(now time.Time, rate float64, burst int) time.Duration {
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens -= 1
+		return 0
+	}
+	if rate <= 0 {
+		return time.Hour
+	}
+	return time.Duration((1 - b.tokens) / rate * float64(time.Second))
+}
+
+type clientBuckets struct {
+	cheap     tokenBucket
+	expensive tokenBucket
+	lastSeen  time.Time
+}
+
+// RateLimiter keeps the buckets of every client seen recently, shared by the HTTP and gRPC servers so a client has one budget
+type RateLimiter struct {
+	enabled        bool
+	cheapRate      float64
+	cheapBurst     int
+	expensiveRate  float64
+	expensiveBurst int
+	maxClients     int
+
+	lock    sync.Mutex
+	clients map[string]*clientBuckets
+}
+
+func NewRateLimiter(cfg config.RateLimitConfig) (*RateLimiter, error) {
+	l := &RateLimiter{
+		enabled:        cfg.Enabled,
+		cheapRate:      cfg.CheapRate,
+		cheapBurst:     cfg.CheapBurst,
+		expensiveRate:  cfg.ExpensiveRate,
+		expensiveBurst: cfg.ExpensiveBurst,
+		maxClients:     cfg.MaxClients,
+		clients:        make(map[string]*clientBuckets),
+	}
+	if !l.enabled {
+		return l, nil
+	}
+	if l.cheapRate <= 0 || l.expensiveRate <= 0 {
+		return nil, errors.New("RateLimit rates must be greater than 0")
+	}
+	if l.cheapBurst < 1 || l.expensiveBurst < 1 {
+		return nil, errors.New("RateLimit bursts must be at least 1")
+	}
+	if l.maxClients < 1 {
+		return nil, errors.New("RateLimit.MaxClients must be at least 1")
+	}
+	return l, nil
+}
+
+// allow takes a token of the cost bucket of client, it returns ErrRateLimited with the time to wait when there is none
+func (l *RateLimiter) allow(client string, cost callCost) (time.Duration, error) {
+	if !l.enabled {
+		return 0, nil
+	}
+	now := time.Now()
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	buckets, ok := l.clients[client]
+	if !ok {
+		if len(l.clients) >= l.maxClients {
+			l.evictIdle(now)
+		}
+		if len(l.clients) >= l.maxClients {
+			return time.Second, fmt.Errorf("%w: too many clients", ErrRateLimited)
+		}
+		// New clients start with full buckets
+		buckets = &clientBuckets{
+			cheap:     tokenBucket{tokens: float64(l.cheapBurst), last: now},
+			expensive: tokenBucket{tokens: float64(l.expensiveBurst), last: now},
+		}
+		l.clients[client] = buckets
+	}
+	buckets.lastSeen = now
+
+	var wait time.Duration
+	if cost == expensiveCall {
+		wait = buckets.expensive.take(now, l.expensiveRate, l.expensiveBurst)
+	} else {
+		wait = buckets.cheap.take(now, l.cheapRate, l.cheapBurst)
+	}
+	if wait > 0 {
+		return wait, fmt.Errorf("%w: retry in %s", ErrRateLimited, wait.Round(time.Millisecond))
+	}
+	return 0, nil
+}
+
+// evictIdle forgets the clients whose buckets have refilled, they would start over the same way
+func (l *RateLimiter) evictIdle(now time.Time) {
+	idle := time.Duration(math.Max(float64(l.cheapBurst)/l.cheapRate, float64(l.expensiveBurst)/l.expensiveRate) * float64(time.Second))
+	for client, buckets := range l.clients {
+		if now.Sub(buckets.lastSeen) >= idle {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// quota charges the calls of one request to its client
+type quota struct {
+	limiter *RateLimiter
+	client  string
+	// Longest wait returned to a limited call of the request, 0 when none was limited
+	retryAfter time.Duration
+}
+
+// allow is safe on a nil quota, which never limits
+func (q *quota) allow(cost callCost) error {
+	if q == nil {
+		return nil
+	}
+	wait, err := q.limiter.allow(q.client, cost)
+	if err != nil && wait > q.retryAfter {
+		q.retryAfter = wait
+	}
+	return err
+}
+
+type quotaKey struct{}
+
+func withQuota(ctx context.Context, q *quota) context.Context {
+	return context.WithValue(ctx, quotaKey{}, q)
+}
+
+func quotaFrom(ctx context.Context) *quota {
+	q, _ := ctx.Value(quotaKey{}).(*quota)
+	return q
+}
+
+// clientKey identifies the caller by its API key if it has one, by its IP address otherwise
+func clientKey(c *caller, remoteAddr string) string {
+	if c.apiKey != "" {
+		return "key:" + c.apiKey
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return "ip:" + host
+}
+
+// apiKeyOf hashes a token so the limiter table doesn't hold credentials
+func apiKeyOf(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// handler attaches the quota of the client to the request, it must run after the authenticator
+func (l *RateLimiter) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+		q := &quota{limiter: l, client: clientKey(callerFrom(r.Context()), r.RemoteAddr)}
+		next.ServeHTTP(w, r.WithContext(withQuota(r.Context(), q)))
+	})
+}
+
+// limit rejects the request with 429 when its client has no token left in the cost bucket
+// Websockets and event streams pay once when they connect
+func limit(cost callCost, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := quotaFrom(r.Context())
+		if err := q.allow(cost); err != nil {
+			writeRateLimitError(w, q.retryAfter, err)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func setRetryAfter(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
+
+func writeRateLimitError(w http.ResponseWriter, wait time.Duration, err error) {
+	setRetryAfter(w, wait)
+	writeRestJson(w, http.StatusTooManyRequests, RestError{Error: toError(err)})
+}
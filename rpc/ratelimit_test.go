@@ -0,0 +1,47 @@
+package rpc
+
+import (
+	"context"
+	"dummy-chain/common/config"
+	"dummy-chain/rpc/pb"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimitSharedByHttpAndGrpc(t *testing.T) {
+	limiter, err := NewRateLimiter(config.RateLimitConfig{
+		Enabled:        true,
+		CheapRate:      0.001,
+		CheapBurst:     10,
+		ExpensiveRate:  0.001,
+		ExpensiveBurst: 1,
+		MaxClients:     10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := &authenticator{}
+
+	handler := auth.handler(limiter.handler(limit(expensiveCall, func(w http.ResponseWriter, r *http.Request) {})))
+	request := httptest.NewRequest(http.MethodGet, "/events", nil)
+	request.RemoteAddr = "192.0.2.1:1000"
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("the first expensive call must pass, got %d", recorder.Code)
+	}
+
+	// Same client over gRPC, from another port
+	g := &GrpcServer{auth: auth, limiter: limiter}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 2000}})
+	err = g.authorize(ctx, pb.Chain_StreamBlocks_FullMethodName)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("the gRPC call must share the HTTP budget, got %v", err)
+	}
+}
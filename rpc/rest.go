@@ -0,0 +1,276 @@
+package rpc
+
+import (
+	"dummy-chain/common/types"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// TransactionSubmission is the body of POST /txs
+type TransactionSubmission struct {
+	// Base64 of the gob encoded signed transaction, the same payload as chain.SendTransaction
+	Transaction string
+}
+
+// SubmittedTransaction is the reply of POST /txs
+type SubmittedTransaction struct {
+	Hash string
+}
+
+// RestError is the body of every non 2xx REST reply, it carries the same error object as JSON-RPC
+type RestError struct {
+	Error *Error
+}
+
+type restParam struct {
+	name        string
+	in          string
+	description string
+	schemaType  string
+	required    bool
+}
+
+// restRoute is one resource endpoint, the same table drives the mux and the OpenAPI document
+type restRoute struct {
+	method      string
+	path        string
+	operationId string
+	summary     string
+	params      []restParam
+	request     interface{}
+	response    interface{}
+	status      int
+	permission  Permission
+	cost        callCost
+	handler     func(s *Server, r *http.Request) (interface{}, error)
+}
+
+var restRoutes = []restRoute{
+	{
+		method:      http.MethodGet,
+		path:        "/blocks/{id}",
+		operationId: "getBlock",
+		summary:     "Block with its transactions, by height or by hash",
+		params: []restParam{
+			{name: "id", in: "path", description: "Block height or 0x prefixed block hash", schemaType: "string", required: true},
+		},
+		response:   types.BlockInfo{},
+		status:     http.StatusOK,
+		permission: ReadPermission,
+		cost:       cheapCall,
+		handler:    (*Server).restGetBlock,
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/txs/{hash}",
+		operationId: "getTransaction",
+		summary:     "Committed transaction by hash",
+		params: []restParam{
+			{name: "hash", in: "path", description: "0x prefixed transaction hash", schemaType: "string", required: true},
+		},
+		response:   types.TransactionInfo{},
+		status:     http.StatusOK,
+		permission: ReadPermission,
+		cost:       cheapCall,
+		handler:    (*Server).restGetTransaction,
+	},
+	{
+		method:      http.MethodPost,
+		path:        "/txs",
+		operationId: "sendTransaction",
+		summary:     "Submit a signed transaction to the mempool",
+		request:     TransactionSubmission{},
+		response:    SubmittedTransaction{},
+		status:      http.StatusAccepted,
+		permission:  SendPermission,
+		cost:        expensiveCall,
+		handler:     (*Server).restSendTransaction,
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/accounts/{address}",
+		operationId: "getAccount",
+		summary:     "Balance and nonce of an account",
+		params: []restParam{
+			{name: "address", in: "path", description: "0x prefixed account address", schemaType: "string", required: true},
+		},
+		response:   types.AccountInfo{},
+		status:     http.StatusOK,
+		permission: ReadPermission,
+		cost:       cheapCall,
+		handler:    (*Server).restGetAccount,
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/accounts/{address}/txs",
+		operationId: "getAccountTransactions",
+		summary:     "Transactions sent or received by an account, newest first",
+		params: []restParam{
+			{name: "address", in: "path", description: "0x prefixed account address", schemaType: "string", required: true},
+			{name: "offset", in: "query", description: "Number of newest transactions to skip", schemaType: "integer"},
+			{name: "limit", in: "query", description: "Page size, at most 100", schemaType: "integer"},
+		},
+		response:   types.TransactionInfoList{},
+		status:     http.StatusOK,
+		permission: ReadPermission,
+		cost:       expensiveCall,
+		handler:    (*Server).restGetAccountTransactions,
+	},
+}
+
+// registerRest adds the resource endpoints and /openapi.json to the mux
+func (s *Server) registerRest(mux *http.ServeMux) error {
+	document, err := json.Marshal(openApiDocument(restRoutes))
+	if err != nil {
+		return err
+	}
+	mux.HandleFunc("GET /openapi.json", limit(cheapCall, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(document)
+	}))
+
+	for _, route := range restRoutes {
+		route := route
+		mux.HandleFunc(route.method+" "+route.path, require(route.permission, limit(route.cost, func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, s.maxBodySize)
+			result, err := route.handler(s, r)
+			if err != nil {
+				writeRestError(w, err)
+				return
+			}
+			writeRestJson(w, route.status, result)
+		})))
+	}
+	return nil
+}
+
+func (s *Server) restGetBlock(r *http.Request) (interface{}, error) {
+	id := r.PathValue("id")
+	var block types.BlockInfo
+	if height, err := strconv.ParseUint(id, 10, 64); err == nil {
+		if err = s.service.GetBlockByHeight(height, &block); err != nil {
+			return nil, err
+		}
+		return block, nil
+	}
+
+	hash, err := parseHash(id)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.service.GetBlockByHash(hash, &block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (s *Server) restGetTransaction(r *http.Request) (interface{}, error) {
+	hash, err := parseHash(r.PathValue("hash"))
+	if err != nil {
+		return nil, err
+	}
+	var tx types.TransactionInfo
+	if err = s.service.GetTransactionByHash(hash, &tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (s *Server) restSendTransaction(r *http.Request) (interface{}, error) {
+	var submission TransactionSubmission
+	if err := json.NewDecoder(r.Body).Decode(&submission); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidParams, err.Error())
+	}
+	tx, err := s.service.submitTransaction(submission.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	return SubmittedTransaction{Hash: tx.Hash.String()}, nil
+}
+
+func (s *Server) restGetAccount(r *http.Request) (interface{}, error) {
+	address, err := parseAddress(r.PathValue("address"))
+	if err != nil {
+		return nil, err
+	}
+	var account types.AccountInfo
+	if err = s.service.GetAccountInfo(address, &account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (s *Server) restGetAccountTransactions(r *http.Request) (interface{}, error) {
+	address, err := parseAddress(r.PathValue("address"))
+	if err != nil {
+		return nil, err
+	}
+	query := AccountTransactionsQuery{Address: address}
+	if query.Offset, err = queryInt(r, "offset"); err != nil {
+		return nil, err
+	}
+	if query.Limit, err = queryInt(r, "limit"); err != nil {
+		return nil, err
+	}
+
+	var list types.TransactionInfoList
+	if err = s.service.GetAccountTransactions(query, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func parseHash(value string) (ecommon.Hash, error) {
+	data, err := hexutil.Decode(value)
+	if err != nil || len(data) != ecommon.HashLength {
+		return ecommon.Hash{}, fmt.Errorf("%w: invalid hash %s", ErrInvalidParams, value)
+	}
+	return ecommon.BytesToHash(data), nil
+}
+
+func parseAddress(value string) (ecommon.Address, error) {
+	if !ecommon.IsHexAddress(value) {
+		return ecommon.Address{}, fmt.Errorf("%w: invalid address %s", ErrInvalidParams, value)
+	}
+	return ecommon.HexToAddress(value), nil
+}
+
+func queryInt(r *http.Request, name string) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid %s", ErrInvalidParams, name)
+	}
+	return number, nil
+}
+
+func writeRestError(w http.ResponseWriter, err error) {
+	rpcErr := toError(err)
+	status := http.StatusInternalServerError
+	switch rpcErr.Code {
+	case NotFoundCode:
+		status = http.StatusNotFound
+	case InvalidParamsCode, InvalidTransactionCode:
+		status = http.StatusBadRequest
+	}
+	writeRestJson(w, status, RestError{Error: rpcErr})
+}
+
+func writeRestJson(w http.ResponseWriter, status int, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		status = http.StatusInternalServerError
+		data, _ = json.Marshal(RestError{Error: newError(InternalErrorCode, err.Error())})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
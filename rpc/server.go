@@ -1,48 +1,171 @@
 package rpc
 
 import (
+	"context"
+	"dummy-chain/common/config"
 	"dummy-chain/storage"
 	"io"
+	"mime"
+	"net"
 	"net/http"
-	"net/rpc"
-	"net/rpc/jsonrpc"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/pkg/errors"
 )
 
+// Time allowed to read the request headers, independent of the configured read timeout
+const readHeaderTimeout = 10 * time.Second
+
 type Server struct {
-	server *rpc.Server
+	registry *registry
+	service  *Service
+	storage  *storage.BadgerDb
+	eventBus *EventBus
+	mux      *http.ServeMux
+
+	graphqlSchema *graphql.Schema
+
+	config      config.RpcConfig
+	cors        *corsPolicy
+	auth        *authenticator
+	limiter     *RateLimiter
+	upgrader    websocket.Upgrader
+	httpServer  *http.Server
+	maxBodySize int64
+	// Cancelled on Stop so long lived streams end and let the shutdown finish
+	baseContext context.Context
+	cancel      context.CancelFunc
 }
 
-func NewServer(storage *storage.BadgerDb, memPool *MemoryPool) (*Server, error) {
-	newServer := rpc.NewServer()
+func NewServer(storage *storage.BadgerDb, memPool *MemoryPool, eventBus *EventBus, backupDir string, peers PeerManager, rpcConfig config.RpcConfig, authConfig config.AuthConfig, limiter *RateLimiter) (*Server, error) {
+	newRegistry := newRegistry()
 	newService := NewService(storage, memPool)
-	if errRegister := newServer.RegisterName("chain", newService); errRegister != nil {
+	if errRegister := newRegistry.register("chain", newService); errRegister != nil {
 		return nil, errRegister
 	}
-	return &Server{
-		server: newServer,
-	}, nil
-}
+	newAdminService := NewAdminService(storage, backupDir, peers)
+	if errRegister := newRegistry.register("admin", newAdminService); errRegister != nil {
+		return nil, errRegister
+	}
+	newEthService := NewEthService(storage, memPool)
+	if errRegister := newRegistry.registerEthStyle("eth", newEthService); errRegister != nil {
+		return nil, errRegister
+	}
+	if errRegister := newRegistry.registerEthStyle("net", &NetService{}); errRegister != nil {
+		return nil, errRegister
+	}
+	schema, err := newGraphqlSchema(storage)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := newAuthenticator(authConfig)
+	if err != nil {
+		return nil, err
+	}
+	server := &Server{
+		registry: newRegistry,
+		service:  newService,
+		storage:  storage,
+		eventBus: eventBus,
+		mux:      http.NewServeMux(),
 
-func (s *Server) Start() error {
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != "POST" {
-			w.WriteHeader(405)
-			return
-		}
+		graphqlSchema: schema,
 
-		conn := &HttpConn{in: r.Body, out: w}
-		codec := jsonrpc.NewServerCodec(conn)
+		config:      rpcConfig,
+		cors:        newCorsPolicy(rpcConfig.CorsOrigins),
+		auth:        auth,
+		limiter:     limiter,
+		maxBodySize: rpcConfig.MaxBodySize,
+	}
+	if server.maxBodySize <= 0 {
+		return nil, errors.New("Rpc.MaxBodySize must be greater than 0")
+	}
+	server.upgrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     server.cors.checkWebSocketOrigin,
+	}
+	server.baseContext, server.cancel = context.WithCancel(context.Background())
+	server.httpServer = &http.Server{
+		Addr:              rpcConfig.Address,
+		Handler:           server.cors.handler(server.auth.handler(server.limiter.handler(server.mux))),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       time.Duration(rpcConfig.ReadTimeout) * time.Second,
+		WriteTimeout:      time.Duration(rpcConfig.WriteTimeout) * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return server.baseContext
+		},
+	}
+	if auth.clientCas != nil {
+		if !rpcConfig.TlsEnabled() {
+			return nil, errors.New("Auth.ClientCaFile needs Rpc.TlsCertFile and Rpc.TlsKeyFile")
+		}
+		server.httpServer.TLSConfig = auth.tlsConfig()
+	}
+	server.mux.HandleFunc("/", server.serveJsonRpc)
+	server.mux.HandleFunc("/ws", require(ReadPermission, limit(expensiveCall, server.serveWebSocket)))
+	server.mux.HandleFunc("/events", require(ReadPermission, limit(expensiveCall, server.serveEvents)))
+	server.mux.HandleFunc("/graphql", require(ReadPermission, limit(expensiveCall, server.serveGraphql)))
+	if errRegister := server.registerRest(server.mux); errRegister != nil {
+		return nil, errRegister
+	}
+	return server, nil
+}
 
-		s.server.ServeCodec(codec)
-	})
-	return http.ListenAndServe(":12345", nil)
+// Start blocks serving until Stop is called
+func (s *Server) Start() error {
+	var err error
+	if s.config.TlsEnabled() {
+		err = s.httpServer.ListenAndServeTLS(s.config.TlsCertFile, s.config.TlsKeyFile)
+	} else {
+		err = s.httpServer.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
 
-type HttpConn struct {
-	in  io.Reader
-	out io.Writer
+// Stop closes the listener, ends event streams and websockets and waits for the running requests until ctx is done
+func (s *Server) Stop(ctx context.Context) error {
+	s.cancel()
+	return s.httpServer.Shutdown(ctx)
 }
 
-func (c *HttpConn) Read(p []byte) (n int, err error)  { return c.in.Read(p) }
-func (c *HttpConn) Write(p []byte) (n int, err error) { return c.out.Write(p) }
-func (c *HttpConn) Close() error                      { return nil }
+func (s *Server) serveJsonRpc(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(405)
+		return
+	}
+	// A browser only sends application/json after a CORS preflight, other types could reach here from any page
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBodySize))
+	if err != nil {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	q := quotaFrom(r.Context())
+	reply := s.registry.handle(body, callerFrom(r.Context()), q)
+	if q != nil && q.retryAfter > 0 {
+		// The reply still holds the results of the calls that were not limited
+		setRetryAfter(w, q.retryAfter)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write(reply)
+		return
+	}
+	if reply == nil {
+		// Only notifications, nothing to answer
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(reply)
+}
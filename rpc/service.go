@@ -1,12 +1,10 @@
 package rpc
 
 import (
-	"bytes"
 	"dummy-chain/common"
 	"dummy-chain/common/types"
 	"dummy-chain/storage"
-	"encoding/base64"
-	"encoding/gob"
+	"fmt"
 
 	"github.com/dgraph-io/badger/v4"
 	ecommon "github.com/ethereum/go-ethereum/common"
@@ -57,43 +55,28 @@ func (b *Service) GetCurrenBlockHeight(param *struct{}, reply *uint64) error {
 }
 
 func (b *Service) GetBlockByHash(hash ecommon.Hash, reply *types.BlockInfo) error {
-	block, err := b.storage.GetBlockByHash(hash)
+	entry, err := b.storage.GetBlockWithTransactionsByHash(hash)
 	if err != nil {
-		return err
-	} else if block == nil {
-		return common.ErrNotFound
-	}
-
-	*reply = block.ToInfo()
-
-	for _, txHash := range block.Transactions {
-		tx, err := b.storage.GetTransaction(txHash)
-		if err != nil {
-			return err
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return common.ErrNotFound
 		}
-		reply.Transactions = append(reply.Transactions, tx.ToInfo())
+		return err
 	}
 
+	*reply = entry.ToInfo()
 	return nil
 }
 
 func (b *Service) GetBlockByHeight(height uint64, reply *types.BlockInfo) error {
-	block, err := b.storage.GetBlockByHeight(height)
+	entry, err := b.storage.GetBlockWithTransactionsByHeight(height)
 	if err != nil {
-		return err
-	} else if block == nil {
-		return common.ErrNotFound
-	}
-	*reply = block.ToInfo()
-
-	for _, txHash := range block.Transactions {
-		tx, err := b.storage.GetTransaction(txHash)
-		if err != nil {
-			return err
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return common.ErrNotFound
 		}
-		reply.Transactions = append(reply.Transactions, tx.ToInfo())
+		return err
 	}
 
+	*reply = entry.ToInfo()
 	return nil
 }
 
@@ -102,47 +85,129 @@ type BlockInterval struct {
 	Right uint64
 }
 
+// GetBlocksInterval returns the blocks between Left and Right inclusive, stopping at the current height
+// At most common.MaxBlocksInterval blocks can be requested at once
 func (b *Service) GetBlocksInterval(interval BlockInterval, reply *types.BlockInfoList) error {
+	if interval.Right >= interval.Left && interval.Right-interval.Left >= common.MaxBlocksInterval {
+		return common.ErrIntervalTooLarge
+	}
+
+	entries, err := b.storage.GetBlockRange(interval.Left, interval.Right)
+	if err != nil {
+		return err
+	}
+
 	*reply = types.BlockInfoList{}
-	reply.Count = 0
-	reply.Blocks = make([]types.BlockInfo, 0)
-
-	for i := interval.Left; i <= interval.Right; i++ {
-		block, err := b.storage.GetBlockByHeight(i)
-		if err != nil {
-			if errors.Is(err, badger.ErrKeyNotFound) || errors.Is(err, badger.ErrKeyNotFound) {
-				break
-			}
-			return err
-		}
-		blockInfo := block.ToInfo()
-		for _, txHash := range block.Transactions {
-			tx, err := b.storage.GetTransaction(txHash)
-			if err != nil {
-				return err
-			}
-			blockInfo.Transactions = append(blockInfo.Transactions, tx.ToInfo())
-		}
-		reply.Blocks = append(reply.Blocks, blockInfo)
-		reply.Count += 1
+	reply.Count = uint64(len(entries))
+	reply.Blocks = make([]types.BlockInfo, 0, len(entries))
+	for _, entry := range entries {
+		reply.Blocks = append(reply.Blocks, entry.ToInfo())
 	}
 
 	return nil
 }
 
-func (b *Service) SendTransaction(base64Tx string, reply *bool) error {
-	txBytes, err := base64.StdEncoding.DecodeString(base64Tx)
+// GetHeadersInterval returns the blocks between Left and Right inclusive without their transactions, stopping at the current height
+// At most common.MaxHeadersInterval headers can be requested at once
+func (b *Service) GetHeadersInterval(interval BlockInterval, reply *types.HeaderInfoList) error {
+	if interval.Right >= interval.Left && interval.Right-interval.Left >= common.MaxHeadersInterval {
+		return common.ErrIntervalTooLarge
+	}
+
+	blocks, err := b.storage.GetHeaderRange(interval.Left, interval.Right)
 	if err != nil {
 		return err
 	}
 
-	var transaction types.Transaction
-	if errDecode := gob.NewDecoder(bytes.NewReader(txBytes)).Decode(&transaction); errDecode != nil {
-		return errDecode
+	*reply = types.HeaderInfoList{}
+	reply.Count = uint64(len(blocks))
+	reply.Headers = make([]types.HeaderInfo, 0, len(blocks))
+	for _, block := range blocks {
+		reply.Headers = append(reply.Headers, block.ToHeaderInfo())
 	}
+	return nil
+}
 
-	common.GlobalLogger.Debugf("Received transaction: %s", transaction.String())
-	b.memPool.AddTransaction(&transaction)
+// SyncStatus reports the progress of the node catching up with the chain
+// The validator creates the chain, so it is always synced
+func (b *Service) SyncStatus(param *struct{}, reply *types.SyncStatus) error {
+	height, err := b.storage.GetHeight()
+	if err != nil {
+		return err
+	}
+	*reply = types.SyncStatus{
+		StartingHeight: height,
+		CurrentHeight:  height,
+		HeadersHeight:  height,
+		TargetHeight:   height,
+	}
+	return nil
+}
+
+type AccountTransactionsQuery struct {
+	Address ecommon.Address
+	Offset  int
+	Limit   int
+}
+
+// GetAccountTransactions returns the transactions sent or received by the address, newest first
+// Limit defaults to and is capped at common.MaxAccountTransactions
+func (b *Service) GetAccountTransactions(query AccountTransactionsQuery, reply *types.TransactionInfoList) error {
+	if query.Offset < 0 || query.Limit < 0 {
+		return fmt.Errorf("%w: negative offset or limit", ErrInvalidParams)
+	}
+	limit := query.Limit
+	if limit == 0 || limit > common.MaxAccountTransactions {
+		limit = common.MaxAccountTransactions
+	}
+
+	txs, err := b.storage.GetAccountTransactions(query.Address, query.Offset, limit)
+	if err != nil {
+		return err
+	}
+
+	*reply = types.TransactionInfoList{}
+	reply.Count = uint64(len(txs))
+	reply.Transactions = make([]types.TransactionInfo, 0, len(txs))
+	for _, tx := range txs {
+		reply.Transactions = append(reply.Transactions, tx.ToInfo())
+	}
+	return nil
+}
+
+func (b *Service) SendTransaction(base64Tx string, reply *bool) error {
+	if _, err := b.submitTransaction(base64Tx); err != nil {
+		return err
+	}
 	*reply = true
 	return nil
 }
+
+// submitTransaction decodes and checks a base64 gob transaction and adds it to the mempool
+func (b *Service) submitTransaction(base64Tx string) (*types.Transaction, error) {
+	transaction, err := types.DecodeBase64Transaction(base64Tx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", common.ErrInvalidTransaction, err.Error())
+	}
+	if err = transaction.Validate(); err != nil {
+		return nil, err
+	}
+
+	common.GlobalLogger.Debugf("Received transaction: %s", transaction.String())
+	if err = AddToMemPool(b.storage, b.memPool, transaction); err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// AddToMemPool adds tx unless it was already committed
+// Resubmitting a transaction, pending or committed, succeeds without effect so clients can retry a submission safely
+func AddToMemPool(db *storage.BadgerDb, memPool *MemoryPool, tx *types.Transaction) error {
+	committed, err := db.GetTransaction(tx.Hash)
+	if err == nil && committed != nil {
+		return nil
+	} else if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+		return err
+	}
+	return memPool.AddTransaction(tx)
+}
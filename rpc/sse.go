@@ -0,0 +1,131 @@
+package rpc
+
+import (
+	"dummy-chain/common/types"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	blockEventName   = "block"
+	sseHeartbeatTime = 15 * time.Second
+)
+
+// serveEvents streams every committed block as a Server-Sent Event whose id is the block height
+// A client reconnecting with a Last-Event-ID header (or lastEventId query parameter) first receives every block after that height
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	resume := false
+	var next, height uint64
+	lastEventId := r.Header.Get("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = r.URL.Query().Get("lastEventId")
+	}
+	if lastEventId != "" {
+		lastHeight, err := strconv.ParseUint(lastEventId, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		if height, err = s.storage.GetHeight(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// An id past the tip was never sent by this chain, and MaxUint64 + 1 would wrap to a replay from genesis
+		if lastHeight > height {
+			http.Error(w, "Last-Event-ID is above the current height", http.StatusBadRequest)
+			return
+		}
+		resume = true
+		next = lastHeight + 1
+	}
+
+	// The stream outlives the configured write timeout
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// Subscribe before the backfill so no block is lost in between
+	sub := s.eventBus.Subscribe()
+	defer s.eventBus.Unsubscribe(sub)
+
+	sendEvent := func(block *types.BlockInfo) error {
+		return writeBlockEvent(w, block)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	if resume {
+		var err error
+		if next, err = backfillBlocks(s.storage, next, height, sendEvent); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatTime)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-sub.C:
+			if !ok {
+				// Dropped for being too slow, the client reconnects with its Last-Event-ID
+				return
+			}
+			if event.Kind != NewBlockEvent {
+				continue
+			}
+			if resume {
+				if event.Block.Height < next {
+					continue
+				}
+				if event.Block.Height > next {
+					var err error
+					if next, err = backfillBlocks(s.storage, next, event.Block.Height-1, sendEvent); err != nil {
+						return
+					}
+				}
+			}
+			if err := writeBlockEvent(w, event.Block); err != nil {
+				return
+			}
+			resume = true
+			next = event.Block.Height + 1
+			flusher.Flush()
+		}
+	}
+}
+
+func writeBlockEvent(w http.ResponseWriter, block *types.BlockInfo) error {
+	data, err := json.Marshal(block)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", block.Height, blockEventName, data)
+	return err
+}
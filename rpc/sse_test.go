@@ -0,0 +1,50 @@
+package rpc
+
+import (
+	"dummy-chain/common"
+	"dummy-chain/common/config"
+	"dummy-chain/common/types"
+	"dummy-chain/storage"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+)
+
+// newTestEventServer serves events over a chain of empty blocks up to height
+func newTestEventServer(t *testing.T, height uint64) *Server {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	db, err := storage.NewBadgerDb("test", config.CacheConfig{Blocks: 16, Transactions: 16, Accounts: 16, Heights: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	prevHash := ecommon.Hash{}
+	for h := uint64(0); h <= height; h++ {
+		block := &types.Block{ChainId: common.DummyChainId, Height: h, Timestamp: int64(h), PrevHash: prevHash}
+		block.Hash = block.GetHash()
+		if err = db.SetBlock(block, nil); err != nil {
+			t.Fatal(err)
+		}
+		prevHash = block.Hash
+	}
+	return &Server{storage: db}
+}
+
+func TestServeEventsLastEventId(t *testing.T) {
+	s := newTestEventServer(t, 10)
+
+	for _, id := range []string{"11", strconv.FormatUint(^uint64(0), 10), "-1", "abc"} {
+		request := httptest.NewRequest(http.MethodGet, "/events", nil)
+		request.Header.Set("Last-Event-ID", id)
+		recorder := httptest.NewRecorder()
+		s.serveEvents(recorder, request)
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("Last-Event-ID %s: expected status 400, got %d", id, recorder.Code)
+		}
+	}
+}
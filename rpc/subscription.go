@@ -0,0 +1,135 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+)
+
+// ClientSubscription receives the notifications of a single websocket subscription
+type ClientSubscription struct {
+	conn *websocket.Conn
+	Id   string
+
+	// Closed by Close and by a failed read, it ends the goroutine watching the context
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Subscribe opens a websocket to the server and subscribes to topic
+// The subscription is closed when ctx is cancelled
+// It uses the first healthy endpoint, a caller resubscribing after an error moves to the next one
+func (c *Client) Subscribe(ctx context.Context, topic string, params ...interface{}) (*ClientSubscription, error) {
+	e := c.endpoints.pick()
+	wsUrl, err := websocketUrl(e.url)
+	if err != nil {
+		return nil, err
+	}
+	header := make(http.Header)
+	c.authorize(header)
+	conn, resp, err := c.dialer.DialContext(ctx, wsUrl, header)
+	if err != nil {
+		if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+			body, _ := io.ReadAll(resp.Body)
+			err = httpError(resp, body)
+		}
+		if failover, _, _ := transientError(err); failover && ctx.Err() == nil {
+			c.endpoints.failed(e)
+		}
+		return nil, err
+	}
+
+	if err = conn.WriteJSON(rpcRequest{
+		JsonRpc: "2.0",
+		Method:  subscribeMethod,
+		Params:  append([]interface{}{topic}, params...),
+		Id:      c.nextId.Add(1),
+	}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	var response wsResponse
+	if err = conn.ReadJSON(&response); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if response.Error != nil {
+		conn.Close()
+		return nil, response.Error
+	}
+	id, ok := response.Result.(string)
+	if !ok {
+		conn.Close()
+		return nil, errors.New("invalid subscription id")
+	}
+
+	sub := &ClientSubscription{
+		conn:   conn,
+		Id:     id,
+		closed: make(chan struct{}),
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			sub.Close()
+		case <-sub.closed:
+		}
+	}()
+
+	c.endpoints.succeeded(e)
+	return sub, nil
+}
+
+// Next blocks until the next notification arrives and decodes it into result
+func (s *ClientSubscription) Next(result interface{}) error {
+	for {
+		var message struct {
+			Method string                   `json:"method"`
+			Params SubscriptionNotification `json:"params"`
+		}
+		if err := s.conn.ReadJSON(&message); err != nil {
+			// The websocket can't be read after an error
+			s.Close()
+			return err
+		}
+		if message.Method != notificationMethod || message.Params.Subscription != s.Id {
+			continue
+		}
+		return json.Unmarshal(message.Params.Result, result)
+	}
+}
+
+func (s *ClientSubscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		err = s.conn.Close()
+	})
+	return err
+}
+
+// websocketUrl turns the http(s) RPC url into the matching websocket endpoint
+func websocketUrl(rawUrl string) (string, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return "", errors.Errorf("unsupported rpc url %s", rawUrl)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/ws"
+	return u.String(), nil
+}
@@ -0,0 +1,248 @@
+package rpc
+
+import (
+	"dummy-chain/common/types"
+	"dummy-chain/storage"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+)
+
+const (
+	NewBlocksTopic  = "newBlocks"
+	PendingTxsTopic = "pendingTxs"
+	AccountTopic    = "account"
+
+	subscribeMethod    = "subscribe"
+	unsubscribeMethod  = "unsubscribe"
+	notificationMethod = "subscription"
+
+	wsWriteTimeout = 10 * time.Second
+)
+
+type wsRequest struct {
+	JsonRpc string            `json:"jsonrpc"`
+	Id      json.RawMessage   `json:"id"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+type wsResponse struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  interface{}     `json:"params,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+type SubscriptionNotification struct {
+	Subscription string          `json:"subscription"`
+	Result       json.RawMessage `json:"result"`
+}
+
+// wsConn is one websocket client with all of its subscriptions
+type wsConn struct {
+	conn      *websocket.Conn
+	storage   *storage.BadgerDb
+	eventBus  *EventBus
+	writeLock sync.Mutex
+
+	subscriptions map[string]*EventSubscription
+	nextId        uint64
+	lock          sync.Mutex
+}
+
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	// Hijacked connections are not closed by the server shutdown
+	go func() {
+		<-r.Context().Done()
+		conn.Close()
+	}()
+
+	c := &wsConn{
+		conn:          conn,
+		storage:       s.storage,
+		eventBus:      s.eventBus,
+		subscriptions: make(map[string]*EventSubscription),
+	}
+	defer c.close()
+
+	for {
+		var request wsRequest
+		if err = conn.ReadJSON(&request); err != nil {
+			return
+		}
+
+		var result interface{}
+		var start func()
+		switch request.Method {
+		case subscribeMethod:
+			result, start, err = c.subscribe(request.Params)
+		case unsubscribeMethod:
+			result, err = c.unsubscribe(request.Params)
+		default:
+			err = newError(MethodNotFoundCode, "unknown method "+request.Method)
+		}
+
+		response := wsResponse{
+			JsonRpc: "2.0",
+			Id:      request.Id,
+			Result:  result,
+		}
+		if err != nil {
+			response.Result = nil
+			response.Error = toError(err)
+		}
+		if err = c.write(response); err != nil {
+			return
+		}
+		// Notifications only start once the client knows the subscription id
+		if start != nil {
+			start()
+		}
+	}
+}
+
+func (c *wsConn) subscribe(params []json.RawMessage) (string, func(), error) {
+	if len(params) == 0 {
+		return "", nil, errors.Wrap(ErrInvalidParams, "missing topic")
+	}
+	var topic string
+	if err := json.Unmarshal(params[0], &topic); err != nil {
+		return "", nil, err
+	}
+
+	var address ecommon.Address
+	switch topic {
+	case NewBlocksTopic, PendingTxsTopic:
+	case AccountTopic:
+		if len(params) < 2 {
+			return "", nil, errors.Wrap(ErrInvalidParams, "missing account address")
+		}
+		if err := json.Unmarshal(params[1], &address); err != nil {
+			return "", nil, err
+		}
+	default:
+		return "", nil, errors.Wrapf(ErrInvalidParams, "unknown topic %s", topic)
+	}
+
+	c.lock.Lock()
+	id := strconv.FormatUint(c.nextId, 10)
+	c.nextId += 1
+	sub := c.eventBus.Subscribe()
+	c.subscriptions[id] = sub
+	c.lock.Unlock()
+
+	return id, func() { go c.forward(id, topic, address, sub) }, nil
+}
+
+func (c *wsConn) unsubscribe(params []json.RawMessage) (bool, error) {
+	if len(params) == 0 {
+		return false, errors.Wrap(ErrInvalidParams, "missing subscription id")
+	}
+	var id string
+	if err := json.Unmarshal(params[0], &id); err != nil {
+		return false, err
+	}
+
+	c.lock.Lock()
+	sub, ok := c.subscriptions[id]
+	delete(c.subscriptions, id)
+	c.lock.Unlock()
+
+	if ok {
+		c.eventBus.Unsubscribe(sub)
+	}
+	return ok, nil
+}
+
+// forward turns bus events into notifications until the subscription is closed
+// A subscriber dropped by the bus for being too slow gets the whole connection closed, so it can resync
+func (c *wsConn) forward(id string, topic string, address ecommon.Address, sub *EventSubscription) {
+	for event := range sub.C {
+		var payload interface{}
+		switch {
+		case topic == NewBlocksTopic && event.Kind == NewBlockEvent:
+			payload = event.Block
+		case topic == PendingTxsTopic && event.Kind == PendingTransactionEvent:
+			payload = event.Transaction
+		case topic == AccountTopic && event.Kind == NewBlockEvent && touchesAccount(event.Block, address):
+			account, err := c.storage.GetAccount(address)
+			if err != nil {
+				continue
+			}
+			payload = account.ToInfo()
+		default:
+			continue
+		}
+
+		if err := c.notify(id, payload); err != nil {
+			c.conn.Close()
+			return
+		}
+	}
+
+	c.lock.Lock()
+	_, active := c.subscriptions[id]
+	c.lock.Unlock()
+	if active {
+		c.conn.Close()
+	}
+}
+
+func (c *wsConn) notify(id string, payload interface{}) error {
+	result, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return c.write(wsResponse{
+		JsonRpc: "2.0",
+		Method:  notificationMethod,
+		Params: SubscriptionNotification{
+			Subscription: id,
+			Result:       result,
+		},
+	})
+}
+
+func (c *wsConn) write(message interface{}) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return err
+	}
+	return c.conn.WriteJSON(message)
+}
+
+func (c *wsConn) close() {
+	c.lock.Lock()
+	subscriptions := c.subscriptions
+	c.subscriptions = make(map[string]*EventSubscription)
+	c.lock.Unlock()
+
+	for _, sub := range subscriptions {
+		c.eventBus.Unsubscribe(sub)
+	}
+	c.conn.Close()
+}
+
+func touchesAccount(block *types.BlockInfo, address ecommon.Address) bool {
+	for _, tx := range block.Transactions {
+		if ecommon.HexToAddress(tx.From) == address || ecommon.HexToAddress(tx.To) == address {
+			return true
+		}
+	}
+	return false
+}
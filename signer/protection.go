@@ -0,0 +1,99 @@
+package signer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+)
+
+// protection is the slashing protection record, the last block signed
+// Blocks are created at increasing heights, so refusing anything below it or a different block at its height
+// is enough to never sign two blocks at the same height
+type protection struct {
+	path string
+
+	lock   sync.Mutex
+	Signed bool
+	Height uint64
+	Hash   ecommon.Hash
+}
+
+// loadProtection reads the record at path, a missing file means nothing was signed yet
+func loadProtection(path string) (*protection, error) {
+	p := &protection{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return p, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ProtectedHeight returns the height of the last block signed according to the record at path, ok is false when nothing was signed
+func ProtectedHeight(path string) (height uint64, ok bool, err error) {
+	p, err := loadProtection(path)
+	if err != nil {
+		return 0, false, err
+	}
+	return p.Height, p.Signed, nil
+}
+
+// record checks the block against the last signed one and stores it durably
+func (p *protection) record(height uint64, hash ecommon.Hash) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.Signed {
+		switch {
+		case height < p.Height:
+			return fmt.Errorf("%w: block %d is below the last signed block %d", ErrSlashable, height, p.Height)
+		case height == p.Height && hash != p.Hash:
+			return fmt.Errorf("%w: block %s was already signed at height %d", ErrSlashable, p.Hash, height)
+		case height == p.Height:
+			// Signing the same block again is harmless
+			return nil
+		}
+	}
+
+	signed, lastHeight, lastHash := p.Signed, p.Height, p.Hash
+	p.Signed, p.Height, p.Hash = true, height, hash
+	if err := p.save(); err != nil {
+		p.Signed, p.Height, p.Hash = signed, lastHeight, lastHash
+		return err
+	}
+	return nil
+}
+
+// save writes the record through a synced temporary file, it must be on disk before the signature leaves
+func (p *protection) save() error {
+	data, err := json.MarshalIndent(p, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(p.path), 0700); err != nil {
+		return err
+	}
+	tmp := p.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(data); err == nil {
+		err = file.Sync()
+	}
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.path)
+}
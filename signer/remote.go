@@ -0,0 +1,143 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"dummy-chain/common/types"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const remoteTimeout = 10 * time.Second
+
+// AddressReply is the body of GET /address
+type AddressReply struct {
+	Address ecommon.Address
+}
+
+// SignatureReply is the body of POST /sign/block and POST /sign/transaction
+type SignatureReply struct {
+	Signature []byte
+}
+
+// ErrorReply is the body of every non 2xx reply, 409 Conflict is a block refused by the slashing protection
+type ErrorReply struct {
+	Error string
+}
+
+// Remote signs through a signer process reached over HTTP or a Unix socket
+type Remote struct {
+	baseUrl    string
+	token      string
+	httpClient *http.Client
+	address    ecommon.Address
+}
+
+// NewRemote connects to the signer at rawUrl, http(s)://host:port or unix:///path/to/socket, and asks for its address
+func NewRemote(ctx context.Context, rawUrl string, token string) (*Remote, error) {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+	s := &Remote{
+		baseUrl:    strings.TrimSuffix(rawUrl, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: remoteTimeout},
+	}
+	switch parsed.Scheme {
+	case "http", "https":
+	case "unix":
+		socket := parsed.Path
+		s.baseUrl = "http://signer"
+		s.httpClient.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		}
+	default:
+		return nil, errors.Errorf("unsupported signer url %s", rawUrl)
+	}
+
+	var reply AddressReply
+	if err = s.call(ctx, http.MethodGet, "/address", nil, &reply); err != nil {
+		return nil, errors.Wrap(err, "failed to reach the signer")
+	}
+	s.address = reply.Address
+	return s, nil
+}
+
+func (s *Remote) Address() ecommon.Address {
+	return s.address
+}
+
+func (s *Remote) SignBlock(ctx context.Context, block *types.Block) ([]byte, error) {
+	var reply SignatureReply
+	if err := s.call(ctx, http.MethodPost, "/sign/block", block, &reply); err != nil {
+		return nil, err
+	}
+	// A misbehaving signer must not get a bad block broadcast
+	if signer, err := types.RecoverSigner(block.Hash, reply.Signature); err != nil || signer != s.address {
+		return nil, errors.New("the signer returned an invalid block signature")
+	}
+	return reply.Signature, nil
+}
+
+func (s *Remote) SignTransaction(ctx context.Context, tx *types.Transaction) ([]byte, error) {
+	var reply SignatureReply
+	if err := s.call(ctx, http.MethodPost, "/sign/transaction", tx, &reply); err != nil {
+		return nil, err
+	}
+	if signer, err := types.RecoverSigner(tx.Hash, reply.Signature); err != nil || signer != s.address {
+		return nil, errors.New("the signer returned an invalid transaction signature")
+	}
+	return reply.Signature, nil
+}
+
+func (s *Remote) call(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.baseUrl+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var reply ErrorReply
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&reply)
+		// The signer replies with its own wrapped error, the sentinel is put back on this side
+		switch resp.StatusCode {
+		case http.StatusConflict:
+			return fmt.Errorf("%w: %s", ErrSlashable, strings.TrimPrefix(reply.Error, ErrSlashable.Error()+": "))
+		case http.StatusBadRequest:
+			return fmt.Errorf("%w: %s", ErrInvalidRequest, strings.TrimPrefix(reply.Error, ErrInvalidRequest.Error()+": "))
+		}
+		return errors.Errorf("signer replied %s: %s", resp.Status, reply.Error)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,165 @@
+package signer
+
+import (
+	"context"
+	"crypto/subtle"
+	"dummy-chain/common/types"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	// Largest block or transaction accepted, blocks only carry transaction hashes
+	maxRequestSize = 4 * 1024 * 1024
+	// Time given to a signing request when the signer stops
+	ShutdownTimeout = 5 * time.Second
+)
+
+// Server lets a validator sign with a key it never holds, every block goes through the slashing protection of the signer
+type Server struct {
+	signer     Signer
+	token      string
+	logger     *zap.SugaredLogger
+	httpServer *http.Server
+}
+
+func NewServer(signer Signer, token string, logger *zap.Logger) *Server {
+	s := &Server{
+		signer: signer,
+		token:  token,
+		logger: logger.Sugar(),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /address", s.authorized(s.serveAddress))
+	mux.HandleFunc("POST /sign/block", s.authorized(s.serveSignBlock))
+	mux.HandleFunc("POST /sign/transaction", s.authorized(s.serveSignTransaction))
+	s.httpServer = &http.Server{Handler: mux, ReadTimeout: remoteTimeout, WriteTimeout: remoteTimeout}
+	return s
+}
+
+// Serve accepts requests on address, host:port or unix:///path/to/socket, until Stop is called
+// The socket is only accessible to the owner of the process, and host:port requires a token
+func (s *Server) Serve(address string) error {
+	var listener net.Listener
+	var err error
+	if socket, ok := strings.CutPrefix(address, "unix://"); ok {
+		// A socket left by a crashed signer would block the new one
+		if err = os.Remove(socket); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if listener, err = net.Listen("unix", socket); err != nil {
+			return err
+		}
+		if err = os.Chmod(socket, 0600); err != nil {
+			listener.Close()
+			return err
+		}
+	} else {
+		// Anyone reaching the port could sign transactions draining the account
+		if s.token == "" {
+			return ErrNoToken
+		}
+		if listener, err = net.Listen("tcp", address); err != nil {
+			return err
+		}
+		if !isLoopback(address) {
+			s.logger.Warnf("Signing over plain HTTP on %s, the token and the requests can be read on the network, put a TLS proxy in front of it", address)
+		}
+	}
+
+	s.logger.Infof("Signing for %s on %s", s.signer.Address().Hex(), address)
+	if err = s.httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// isLoopback tells whether host:port only listens on the local machine
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+func (s *Server) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+				writeJson(w, http.StatusUnauthorized, ErrorReply{Error: "invalid token"})
+				return
+			}
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestSize)
+		handler(w, r)
+	}
+}
+
+func (s *Server) serveAddress(w http.ResponseWriter, _ *http.Request) {
+	writeJson(w, http.StatusOK, AddressReply{Address: s.signer.Address()})
+}
+
+func (s *Server) serveSignBlock(w http.ResponseWriter, r *http.Request) {
+	var block types.Block
+	if err := json.NewDecoder(r.Body).Decode(&block); err != nil {
+		writeJson(w, http.StatusBadRequest, ErrorReply{Error: err.Error()})
+		return
+	}
+	signature, err := s.signer.SignBlock(r.Context(), &block)
+	if err != nil {
+		s.logger.Warnf("Refused to sign block %d %s: %s", block.Height, block.Hash.Hex(), err.Error())
+		writeSignError(w, err)
+		return
+	}
+	s.logger.Debugf("Signed block %d %s", block.Height, block.Hash.Hex())
+	writeJson(w, http.StatusOK, SignatureReply{Signature: signature})
+}
+
+func (s *Server) serveSignTransaction(w http.ResponseWriter, r *http.Request) {
+	var tx types.Transaction
+	if err := json.NewDecoder(r.Body).Decode(&tx); err != nil {
+		writeJson(w, http.StatusBadRequest, ErrorReply{Error: err.Error()})
+		return
+	}
+	signature, err := s.signer.SignTransaction(r.Context(), &tx)
+	if err != nil {
+		writeSignError(w, err)
+		return
+	}
+	s.logger.Debugf("Signed transaction %s", tx.Hash.Hex())
+	writeJson(w, http.StatusOK, SignatureReply{Signature: signature})
+}
+
+func writeSignError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrSlashable):
+		status = http.StatusConflict
+	case errors.Is(err, ErrInvalidRequest):
+		status = http.StatusBadRequest
+	}
+	writeJson(w, status, ErrorReply{Error: err.Error()})
+}
+
+func writeJson(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,79 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"dummy-chain/common/types"
+	"fmt"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrSlashable is returned for a block at a height the signer already signed another block at, or below it
+	ErrSlashable = errors.New("refusing to sign a conflicting block")
+	// ErrInvalidRequest is returned for a block or transaction whose hash doesn't match its content or the signer
+	ErrInvalidRequest = errors.New("invalid signing request")
+	// ErrNoToken is returned by Server.Serve for a TCP address without a token
+	ErrNoToken = errors.New("a signer listening on host:port requires Signer.AuthToken")
+)
+
+// Signer signs blocks and transactions for one account, wherever the key lives
+type Signer interface {
+	Address() ecommon.Address
+	// SignBlock returns the signature of the block hash, it must never sign two different blocks at the same height
+	SignBlock(ctx context.Context, block *types.Block) ([]byte, error)
+	// SignTransaction returns the signature of the hash of a transaction sent by Address
+	SignTransaction(ctx context.Context, tx *types.Transaction) ([]byte, error)
+}
+
+// Local signs with a key held in the process
+type Local struct {
+	key        *ecdsa.PrivateKey
+	address    ecommon.Address
+	protection *protection
+}
+
+// NewLocal creates a signer for key, its signed blocks are recorded in the slashing protection file at protectionPath
+func NewLocal(key *ecdsa.PrivateKey, protectionPath string) (*Local, error) {
+	protection, err := loadProtection(protectionPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Local{
+		key:        key,
+		address:    crypto.PubkeyToAddress(key.PublicKey),
+		protection: protection,
+	}, nil
+}
+
+func (s *Local) Address() ecommon.Address {
+	return s.address
+}
+
+func (s *Local) SignBlock(_ context.Context, block *types.Block) ([]byte, error) {
+	if block.Validator != s.address {
+		return nil, fmt.Errorf("%w: block created by %s", ErrInvalidRequest, block.Validator)
+	}
+	if block.Hash != block.GetHash() {
+		return nil, fmt.Errorf("%w: block hash mismatch", ErrInvalidRequest)
+	}
+	// The block is recorded before it is signed. After a crash in between the node signs this exact block again,
+	// which is allowed, any other block at its height is refused
+	if err := s.protection.record(block.Height, block.Hash); err != nil {
+		return nil, err
+	}
+	return crypto.Sign(block.Hash[:], s.key)
+}
+
+func (s *Local) SignTransaction(_ context.Context, tx *types.Transaction) ([]byte, error) {
+	if tx.From != s.address {
+		return nil, fmt.Errorf("%w: transaction sent by %s", ErrInvalidRequest, tx.From)
+	}
+	if tx.Hash != tx.GetHash() {
+		return nil, fmt.Errorf("%w: transaction hash mismatch", ErrInvalidRequest)
+	}
+	return crypto.Sign(tx.Hash[:], s.key)
+}
@@ -0,0 +1,135 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"dummy-chain/common"
+	"dummy-chain/common/types"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func newTestSigner(t *testing.T) (*Local, string) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "slashing-protection.json")
+	s, err := NewLocal(key, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, path
+}
+
+func testBlock(validator ecommon.Address, height uint64, timestamp int64) *types.Block {
+	block := &types.Block{
+		ChainId:   common.DummyChainId,
+		Height:    height,
+		Timestamp: timestamp,
+		Validator: validator,
+	}
+	block.Hash = block.GetHash()
+	return block
+}
+
+func TestSignBlockProtection(t *testing.T) {
+	s, _ := newTestSigner(t)
+	ctx := context.Background()
+
+	first := testBlock(s.Address(), 5, 100)
+	signature, err := s.SignBlock(ctx, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer, errRecover := types.RecoverSigner(first.Hash, signature); errRecover != nil || signer != s.Address() {
+		t.Fatalf("invalid signature: %v", errRecover)
+	}
+
+	// The node signs the same block again after a crash, it must get the same signature
+	again, err := s.SignBlock(ctx, first)
+	if err != nil {
+		t.Fatalf("signing the same block again must be allowed: %v", err)
+	}
+	if !bytes.Equal(again, signature) {
+		t.Fatal("signing the same block again must return the same signature")
+	}
+
+	if _, err = s.SignBlock(ctx, testBlock(s.Address(), 5, 101)); !errors.Is(err, ErrSlashable) {
+		t.Fatalf("another block at the same height must be refused, got %v", err)
+	}
+	if _, err = s.SignBlock(ctx, testBlock(s.Address(), 4, 100)); !errors.Is(err, ErrSlashable) {
+		t.Fatalf("a block below the last one must be refused, got %v", err)
+	}
+	if _, err = s.SignBlock(ctx, testBlock(s.Address(), 6, 105)); err != nil {
+		t.Fatalf("the next block must be signed: %v", err)
+	}
+}
+
+func TestSignBlockInvalidRequest(t *testing.T) {
+	s, path := newTestSigner(t)
+	ctx := context.Background()
+
+	if _, err := s.SignBlock(ctx, testBlock(ecommon.Address{1}, 1, 100)); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("a block of another validator must be refused, got %v", err)
+	}
+	tampered := testBlock(s.Address(), 1, 100)
+	tampered.Timestamp++
+	if _, err := s.SignBlock(ctx, tampered); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("a block whose hash doesn't match must be refused, got %v", err)
+	}
+	// Refused requests must not be recorded
+	if _, signed, err := ProtectedHeight(path); err != nil || signed {
+		t.Fatalf("nothing must be recorded, got signed=%v err=%v", signed, err)
+	}
+}
+
+func TestProtectionSurvivesRestart(t *testing.T) {
+	s, path := newTestSigner(t)
+	ctx := context.Background()
+
+	block := testBlock(s.Address(), 7, 100)
+	if _, err := s.SignBlock(ctx, block); err != nil {
+		t.Fatal(err)
+	}
+	height, signed, err := ProtectedHeight(path)
+	if err != nil || !signed || height != 7 {
+		t.Fatalf("expected block 7 to be recorded, got %d signed=%v err=%v", height, signed, err)
+	}
+
+	restarted, err := NewLocal(s.key, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = restarted.SignBlock(ctx, testBlock(s.Address(), 7, 101)); !errors.Is(err, ErrSlashable) {
+		t.Fatalf("the record must survive a restart, got %v", err)
+	}
+	if _, err = restarted.SignBlock(ctx, block); err != nil {
+		t.Fatalf("the recorded block must still be signed after a restart: %v", err)
+	}
+}
+
+func TestSignTransaction(t *testing.T) {
+	s, _ := newTestSigner(t)
+	ctx := context.Background()
+
+	tx := types.NewTransaction(s.Address(), 0, ecommon.Address{2}, big.NewInt(1))
+	signature, err := s.SignTransaction(ctx, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer, errRecover := types.RecoverSigner(tx.Hash, signature); errRecover != nil || signer != s.Address() {
+		t.Fatalf("invalid signature: %v", errRecover)
+	}
+
+	other := types.NewTransaction(ecommon.Address{3}, 0, ecommon.Address{2}, big.NewInt(1))
+	if _, err = s.SignTransaction(ctx, other); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("a transaction of another account must be refused, got %v", err)
+	}
+}
@@ -17,15 +17,26 @@ func (b *BadgerDb) SetAccount(account *types.Account) error {
 		return err
 	}
 
+	b.commitLock.Lock()
+	defer b.commitLock.Unlock()
 	if err := b.db.Update(func(txn *badger.Txn) error {
 		return txn.Set(getAccountKey(account.Address), buf.Bytes())
 	}); err != nil {
 		return err
 	}
+	b.accountCache.remove(account.Address)
 	return nil
 }
 
+// GetAccount returns a copy of the account, callers are free to modify it
 func (b *BadgerDb) GetAccount(address ecommon.Address) (*types.Account, error) {
+	b.commitLock.RLock()
+	defer b.commitLock.RUnlock()
+
+	if account, ok := b.accountCache.get(address); ok {
+		return copyAccount(account), nil
+	}
+
 	var decodedAccount types.Account
 	if err := b.db.View(func(txn *badger.Txn) error {
 		item, err := txn.Get(getAccountKey(address))
@@ -42,20 +53,35 @@ func (b *BadgerDb) GetAccount(address ecommon.Address) (*types.Account, error) {
 		return nil
 	}); err != nil {
 		if errors.Is(err, badger.ErrKeyNotFound) {
-			return &types.Account{
+			decodedAccount = types.Account{
 				Address: address,
 				Nonce:   0,
 				Balance: big.NewInt(0),
-			}, nil
+			}
+		} else {
+			return nil, err
 		}
-
-		return nil, err
 	}
+	b.accountCache.add(address, copyAccount(&decodedAccount))
 	return &decodedAccount, nil
 }
 
 func (b *BadgerDb) DeleteAccount(address ecommon.Address) error {
-	return b.db.Update(func(txn *badger.Txn) error {
+	b.commitLock.Lock()
+	defer b.commitLock.Unlock()
+	if err := b.db.Update(func(txn *badger.Txn) error {
 		return txn.Delete(getAccountKey(address))
-	})
+	}); err != nil {
+		return err
+	}
+	b.accountCache.remove(address)
+	return nil
+}
+
+func copyAccount(account *types.Account) *types.Account {
+	return &types.Account{
+		Address: account.Address,
+		Nonce:   account.Nonce,
+		Balance: new(big.Int).Set(account.Balance),
+	}
 }
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"bytes"
+	"dummy-chain/common"
+	"dummy-chain/common/types"
+	"math"
+
+	"github.com/dgraph-io/badger/v4"
+	ecommon "github.com/ethereum/go-ethereum/common"
+)
+
+// GetAccountTransactions returns the transactions sent or received by the address, newest first
+// offset skips that many of the newest ones, at most limit are returned
+// Databases written before the index existed need a reindex to see their older transactions
+func (b *BadgerDb) GetAccountTransactions(address ecommon.Address, offset, limit int) ([]*types.Transaction, error) {
+	return b.GetAccountTransactionsInRange(address, 0, math.MaxUint64, offset, limit)
+}
+
+// GetAccountTransactionsInRange is GetAccountTransactions limited to the blocks between fromHeight and toHeight inclusive
+func (b *BadgerDb) GetAccountTransactionsInRange(address ecommon.Address, fromHeight, toHeight uint64, offset, limit int) ([]*types.Transaction, error) {
+	b.commitLock.RLock()
+	defer b.commitLock.RUnlock()
+
+	result := make([]*types.Transaction, 0)
+	if limit <= 0 || toHeight < fromHeight {
+		return result, nil
+	}
+
+	if err := b.db.View(func(txn *badger.Txn) error {
+		prefix := getAccountTxPrefix(address)
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		opts.Reverse = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		// Reverse iteration starts at the last key lower or equal to the seek key
+		last := common.JoinBytes(prefix, common.Uint64ToBytes(toHeight), bytes.Repeat([]byte{0xff}, 8))
+		skipped := 0
+		for it.Seek(last); it.Valid() && len(result) < limit; it.Next() {
+			height := common.BytesToUint64(it.Item().Key()[len(prefix) : len(prefix)+8])
+			if height < fromHeight {
+				break
+			}
+			if skipped < offset {
+				skipped += 1
+				continue
+			}
+			data, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			hash := ecommon.BytesToHash(data)
+			tx, ok := b.transactionCache.get(hash)
+			if !ok {
+				if tx, err = readTransaction(txn, hash); err != nil {
+					return err
+				}
+				b.transactionCache.add(hash, tx)
+			}
+			result = append(result, tx)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"bytes"
+	"dummy-chain/common"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/pkg/errors"
+)
+
+// Number of pending writes badger keeps in flight while loading a backup
+const restoreMaxPendingWrites = 256
+
+// Backup streams a consistent snapshot of every key newer than since and returns the version to use for the next incremental backup
+// It can run while the node keeps writing blocks
+func (b *BadgerDb) Backup(w io.Writer, since uint64) (uint64, error) {
+	return b.db.Backup(w, since)
+}
+
+// BackupToFile writes a full backup to path, the file is only moved in place after it was fully written
+func (b *BadgerDb) BackupToFile(path string) (uint64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return 0, err
+	}
+
+	tmpPath := path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, err
+	}
+	version, err := b.Backup(file, 0)
+	if err == nil {
+		err = file.Sync()
+	}
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return 0, err
+	}
+	return version, os.Rename(tmpPath, path)
+}
+
+// Restore loads a backup created by Backup
+// Unless overwrite is set it refuses to touch a database that already holds a chain
+func (b *BadgerDb) Restore(r io.Reader, overwrite bool) error {
+	b.commitLock.Lock()
+	defer b.commitLock.Unlock()
+	defer b.purgeCaches()
+
+	if _, err := b.getBlockHashByHeight(0); err == nil {
+		if !overwrite {
+			return common.ErrDatabaseNotEmpty
+		}
+		if err = b.db.DropAll(); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, badger.ErrKeyNotFound) {
+		return err
+	}
+
+	return loadBackup(b.db, r)
+}
+
+// BackupHeight returns the height of the chain held by a backup, loading it into an in-memory database
+// It fails on a corrupt or truncated file, so it is the check to run before dropping a database for a restore
+func BackupHeight(r io.Reader) (uint64, error) {
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	if err = loadBackup(db, r); err != nil {
+		return 0, err
+	}
+
+	var height uint64
+	err = db.View(func(txn *badger.Txn) error {
+		item, errGet := txn.Get(getHeightKey())
+		if errors.Is(errGet, badger.ErrKeyNotFound) {
+			return errors.New("the backup holds no chain")
+		} else if errGet != nil {
+			return errGet
+		}
+		return item.Value(func(data []byte) error {
+			return gob.NewDecoder(bytes.NewReader(data)).Decode(&height)
+		})
+	})
+	return height, err
+}
+
+// loadBackup runs badger's Load, which panics on some corrupt lengths instead of failing
+func loadBackup(db *badger.DB, r io.Reader) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = errors.Errorf("corrupt backup: %v", recovered)
+		}
+	}()
+	return db.Load(r, restoreMaxPendingWrites)
+}
+
+func (b *BadgerDb) Close() error {
+	return b.db.Close()
+}
+
+// BackupFilePath resolves a backup name inside dir, an empty name gets a timestamped default
+// Only plain file names are accepted so remote callers can't write outside of dir
+func BackupFilePath(dir, name string) (string, error) {
+	if name == "" {
+		name = fmt.Sprintf("chain-%s.bak", time.Now().UTC().Format("20060102-150405"))
+	}
+	if name != filepath.Base(name) || name == "." || name == ".." {
+		return "", errors.Errorf("invalid backup name %q", name)
+	}
+	return filepath.Join(dir, name), nil
+}
@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBackupHeightInvalid(t *testing.T) {
+	b := newTestDb(t)
+	if err := b.SetBlock(testBlock(0, [32]byte{}, 0), nil); err != nil {
+		t.Fatal(err)
+	}
+	var backup bytes.Buffer
+	if _, err := b.Backup(&backup, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string][]byte{
+		"empty":     {},
+		"truncated": backup.Bytes()[:backup.Len()/2],
+		"garbage":   []byte("not a backup at all"),
+	}
+	for name, data := range tests {
+		if _, err := BackupHeight(bytes.NewReader(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
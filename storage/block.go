@@ -12,56 +12,20 @@ import (
 )
 
 func (b *BadgerDb) SetBlock(block *types.Block, txs []*types.Transaction) error {
+	// Readers wait until both the commit and the cache invalidation are done
+	b.commitLock.Lock()
+	defer b.commitLock.Unlock()
+
 	// Atomic update
 	if err := b.db.Update(func(txn *badger.Txn) error {
-		// Store the block
-		var blockBuf bytes.Buffer
-		if err := gob.NewEncoder(&blockBuf).Encode(*block); err != nil {
-			return err
-		} else if err = txn.Set(getBlockKey(block.Hash), blockBuf.Bytes()); err != nil {
-			return err
-		}
-
-		// Link the height to the hash
-		if err := txn.Set(getHeightToHashKey(block.Height), block.Hash.Bytes()); err != nil {
+		if err := setHeader(txn, block); err != nil {
 			return err
 		}
-
-		var heightBuf bytes.Buffer
-		if err := gob.NewEncoder(&heightBuf).Encode(block.Height); err != nil {
+		if err := clearPendingBlock(txn, block.Height); err != nil {
 			return err
-		} else if err = txn.Set(getHeightKey(), heightBuf.Bytes()); err != nil {
-			return err
-		}
-
-		accountsCache := make(map[ecommon.Address]*types.Account)
-		getAccount := func(address ecommon.Address) (*types.Account, error) {
-			if acc, ok := accountsCache[address]; ok {
-				return acc, nil
-			}
-			// Use the same txn
-			item, err := txn.Get(getAccountKey(address))
-			if errors.Is(err, badger.ErrKeyNotFound) {
-				return &types.Account{
-					Address: address,
-					Nonce:   0,
-					Balance: big.NewInt(0),
-				}, nil
-			} else if err != nil {
-				return nil, err
-			}
-
-			var account types.Account
-			data, err := item.ValueCopy(nil)
-			if err != nil {
-				return nil, err
-			} else if err = gob.NewDecoder(bytes.NewReader(data)).Decode(&account); err != nil {
-				return nil, err
-			}
-			return &account, err
 		}
 
-		// Store transactions and update accounts cache
+		// Store transactions
 		for _, tx := range txs {
 			var txBuf bytes.Buffer
 			if err := gob.NewEncoder(&txBuf).Encode(*tx); err != nil {
@@ -69,106 +33,215 @@ func (b *BadgerDb) SetBlock(block *types.Block, txs []*types.Transaction) error
 			} else if err = txn.Set(getTransactionKey(tx.Hash), txBuf.Bytes()); err != nil {
 				return err
 			}
-
-			from, err := getAccount(tx.From)
-			if err != nil {
-				return err
-			}
-			from.Balance.Sub(from.Balance, tx.Value)
-			from.Nonce = tx.Nonce + 1
-			accountsCache[tx.From] = from
-
-			to, err := getAccount(tx.To)
-			if err != nil {
-				return err
-			}
-			to.Balance.Add(to.Balance, tx.Value)
-			accountsCache[tx.To] = to
 		}
 
-		// Store updated accounts
-		for _, acc := range accountsCache {
-			var accountBuf bytes.Buffer
-			if err := gob.NewEncoder(&accountBuf).Encode(*acc); err != nil {
-				return err
-			} else if err = txn.Set(getAccountKey(acc.Address), accountBuf.Bytes()); err != nil {
-				return err
-			}
-		}
-		return nil
+		return applyTransactions(txn, txs)
 	}); err != nil {
 		return err
 	}
 
+	b.heightCache.remove(block.Height)
+	b.blockCache.remove(block.Hash)
+	for _, tx := range txs {
+		b.transactionCache.remove(tx.Hash)
+		b.accountCache.remove(tx.From)
+		b.accountCache.remove(tx.To)
+	}
 	return nil
 }
 
-func (b *BadgerDb) GetBlockByHash(hash ecommon.Hash) (*types.Block, error) {
-	var decodedBlock types.Block
-	if err := b.db.View(func(txn *badger.Txn) error {
-		item, err := txn.Get(getBlockKey(hash))
+// setHeader stores the block, links its height to its hash and makes it the current height
+func setHeader(txn *badger.Txn, block *types.Block) error {
+	var blockBuf bytes.Buffer
+	if err := gob.NewEncoder(&blockBuf).Encode(*block); err != nil {
+		return err
+	} else if err = txn.Set(getBlockKey(block.Hash), blockBuf.Bytes()); err != nil {
+		return err
+	}
+
+	// Link the height to the hash
+	if err := txn.Set(getHeightToHashKey(block.Height), block.Hash.Bytes()); err != nil {
+		return err
+	}
+
+	var heightBuf bytes.Buffer
+	if err := gob.NewEncoder(&heightBuf).Encode(block.Height); err != nil {
+		return err
+	}
+	return txn.Set(getHeightKey(), heightBuf.Bytes())
+}
+
+// applyTransactions moves the balances and bumps the nonces of all accounts touched by the transactions
+// and indexes the transactions of every account. The transactions must be in block order.
+// It must be called inside the same badger transaction that stores the block
+func applyTransactions(txn *badger.Txn, txs []*types.Transaction) error {
+	accountsCache := make(map[ecommon.Address]*types.Account)
+	getAccount := func(address ecommon.Address) (*types.Account, error) {
+		if acc, ok := accountsCache[address]; ok {
+			return acc, nil
+		}
+		// Use the same txn
+		item, err := txn.Get(getAccountKey(address))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return &types.Account{
+				Address: address,
+				Nonce:   0,
+				Balance: big.NewInt(0),
+			}, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		var account types.Account
+		data, err := item.ValueCopy(nil)
 		if err != nil {
+			return nil, err
+		} else if err = gob.NewDecoder(bytes.NewReader(data)).Decode(&account); err != nil {
+			return nil, err
+		}
+		return &account, err
+	}
+
+	// Update accounts cache
+	for i, tx := range txs {
+		if err := txn.Set(getAccountTxKey(tx.From, tx.BlockHeight, uint64(i)), tx.Hash.Bytes()); err != nil {
 			return err
 		}
-		data, err := item.ValueCopy(nil)
+		if tx.To != tx.From {
+			if err := txn.Set(getAccountTxKey(tx.To, tx.BlockHeight, uint64(i)), tx.Hash.Bytes()); err != nil {
+				return err
+			}
+		}
+
+		from, err := getAccount(tx.From)
 		if err != nil {
 			return err
 		}
-		if errDecode := gob.NewDecoder(bytes.NewReader(data)).Decode(&decodedBlock); errDecode != nil {
-			return errDecode
+		from.Balance.Sub(from.Balance, tx.Value)
+		from.Nonce = tx.Nonce + 1
+		accountsCache[tx.From] = from
+
+		to, err := getAccount(tx.To)
+		if err != nil {
+			return err
 		}
-		return nil
+		to.Balance.Add(to.Balance, tx.Value)
+		accountsCache[tx.To] = to
+	}
+
+	// Store updated accounts
+	for _, acc := range accountsCache {
+		var accountBuf bytes.Buffer
+		if err := gob.NewEncoder(&accountBuf).Encode(*acc); err != nil {
+			return err
+		} else if err = txn.Set(getAccountKey(acc.Address), accountBuf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetBlockByHash returns a block that may be shared with the cache, it must not be modified
+func (b *BadgerDb) GetBlockByHash(hash ecommon.Hash) (*types.Block, error) {
+	b.commitLock.RLock()
+	defer b.commitLock.RUnlock()
+
+	return b.getBlockByHash(hash)
+}
+
+func (b *BadgerDb) GetBlockHashByHeight(height uint64) (*ecommon.Hash, error) {
+	b.commitLock.RLock()
+	defer b.commitLock.RUnlock()
+
+	return b.getBlockHashByHeight(height)
+}
+
+// GetBlockByHeight returns a block that may be shared with the cache, it must not be modified
+func (b *BadgerDb) GetBlockByHeight(height uint64) (*types.Block, error) {
+	b.commitLock.RLock()
+	defer b.commitLock.RUnlock()
+
+	hash, err := b.getBlockHashByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	return b.getBlockByHash(*hash)
+}
+
+func (b *BadgerDb) DeleteBlock(hash ecommon.Hash) error {
+	b.commitLock.Lock()
+	defer b.commitLock.Unlock()
+	if err := b.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(getBlockKey(hash))
+	}); err != nil {
+		return err
+	}
+	b.blockCache.remove(hash)
+	return nil
+}
+
+// getBlockByHash reads through the block cache, the caller must hold commitLock
+func (b *BadgerDb) getBlockByHash(hash ecommon.Hash) (*types.Block, error) {
+	if block, ok := b.blockCache.get(hash); ok {
+		return block, nil
+	}
+
+	var decodedBlock *types.Block
+	if err := b.db.View(func(txn *badger.Txn) error {
+		var err error
+		decodedBlock, err = readBlock(txn, hash)
+		return err
 	}); err != nil {
 		return nil, err
 	}
-	return &decodedBlock, nil
+	b.blockCache.add(hash, decodedBlock)
+	return decodedBlock, nil
 }
 
-func (b *BadgerDb) GetBlockHashByHeight(height uint64) (*ecommon.Hash, error) {
+// getBlockHashByHeight reads through the height cache, the caller must hold commitLock
+func (b *BadgerDb) getBlockHashByHeight(height uint64) (*ecommon.Hash, error) {
+	if hash, ok := b.heightCache.get(height); ok {
+		return &hash, nil
+	}
+
 	var decodedHash ecommon.Hash
 	if err := b.db.View(func(txn *badger.Txn) error {
-		item, err := txn.Get(getHeightToHashKey(height))
-		if err != nil {
-			return err
-		}
-		data, err := item.ValueCopy(nil)
-		if err != nil {
-			return err
-		}
-		decodedHash = ecommon.BytesToHash(data)
-		return nil
+		var err error
+		decodedHash, err = readBlockHashByHeight(txn, height)
+		return err
 	}); err != nil {
 		return nil, err
 	}
+	b.heightCache.add(height, decodedHash)
 	return &decodedHash, nil
 }
 
-func (b *BadgerDb) GetBlockByHeight(height uint64) (*types.Block, error) {
-	hash, err := b.GetBlockHashByHeight(height)
+// readBlock decodes a block inside an already opened transaction
+func readBlock(txn *badger.Txn, hash ecommon.Hash) (*types.Block, error) {
+	item, err := txn.Get(getBlockKey(hash))
 	if err != nil {
 		return nil, err
 	}
-	var decodedBlock types.Block
-	if err = b.db.View(func(txn *badger.Txn) error {
-		item, err := txn.Get(getBlockKey(*hash))
-		if err != nil {
-			return err
-		}
-		data, err := item.ValueCopy(nil)
-		if err != nil {
-			return err
-		} else if errDecode := gob.NewDecoder(bytes.NewReader(data)).Decode(&decodedBlock); errDecode != nil {
-			return errDecode
-		}
-		return nil
-	}); err != nil {
+	data, err := item.ValueCopy(nil)
+	if err != nil {
 		return nil, err
 	}
+	var decodedBlock types.Block
+	if errDecode := gob.NewDecoder(bytes.NewReader(data)).Decode(&decodedBlock); errDecode != nil {
+		return nil, errDecode
+	}
 	return &decodedBlock, nil
 }
 
-func (b *BadgerDb) DeleteBlock(hash ecommon.Hash) error {
-	return b.db.Update(func(txn *badger.Txn) error {
-		return txn.Delete(getBlockKey(hash))
-	})
+// readBlockHashByHeight reads the height index inside an already opened transaction
+func readBlockHashByHeight(txn *badger.Txn, height uint64) (ecommon.Hash, error) {
+	item, err := txn.Get(getHeightToHashKey(height))
+	if err != nil {
+		return ecommon.Hash{}, err
+	}
+	data, err := item.ValueCopy(nil)
+	if err != nil {
+		return ecommon.Hash{}, err
+	}
+	return ecommon.BytesToHash(data), nil
 }
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"dummy-chain/common"
+	"dummy-chain/common/types"
+
+	"github.com/dgraph-io/badger/v4"
+	ecommon "github.com/ethereum/go-ethereum/common"
+)
+
+// BlockWithTransactions is a block together with its decoded transactions, in block order
+type BlockWithTransactions struct {
+	Block        *types.Block
+	Transactions []*types.Transaction
+}
+
+// GetBlockRange reads all blocks between left and right inclusive, with their transactions, inside a single badger transaction
+// The range stops at the first missing height, so asking past the tip returns only the existing blocks
+func (b *BadgerDb) GetBlockRange(left, right uint64) ([]*BlockWithTransactions, error) {
+	result := make([]*BlockWithTransactions, 0)
+	if err := b.viewRange(left, right, func(txn *badger.Txn, hash ecommon.Hash) error {
+		entry, err := b.readBlockWithTransactions(txn, hash)
+		if err != nil {
+			return err
+		}
+		result = append(result, entry)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetHeaderRange reads the blocks between left and right inclusive without their transactions, stopping like GetBlockRange
+func (b *BadgerDb) GetHeaderRange(left, right uint64) ([]*types.Block, error) {
+	result := make([]*types.Block, 0)
+	if err := b.viewRange(left, right, func(txn *badger.Txn, hash ecommon.Hash) error {
+		block, err := b.readCachedBlock(txn, hash)
+		if err != nil {
+			return err
+		}
+		result = append(result, block)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// viewRange calls visit with the hash of every block between left and right inclusive, in height order
+// It stops at the first missing height
+func (b *BadgerDb) viewRange(left, right uint64, visit func(txn *badger.Txn, hash ecommon.Hash) error) error {
+	b.commitLock.RLock()
+	defer b.commitLock.RUnlock()
+
+	if right < left {
+		return nil
+	}
+	return b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = heightToHashPrefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		expected := left
+		for it.Seek(getHeightToHashKey(left)); it.Valid(); it.Next() {
+			item := it.Item()
+			height := common.BytesToUint64(item.Key()[len(heightToHashPrefix):])
+			if height != expected || height > right {
+				break
+			}
+			data, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			if err = visit(txn, ecommon.BytesToHash(data)); err != nil {
+				return err
+			}
+
+			if height == right {
+				break
+			}
+			expected += 1
+		}
+		return nil
+	})
+}
+
+// GetBlockWithTransactionsByHash reads a block and its transactions inside a single badger transaction
+func (b *BadgerDb) GetBlockWithTransactionsByHash(hash ecommon.Hash) (*BlockWithTransactions, error) {
+	b.commitLock.RLock()
+	defer b.commitLock.RUnlock()
+
+	var entry *BlockWithTransactions
+	if err := b.db.View(func(txn *badger.Txn) error {
+		var err error
+		entry, err = b.readBlockWithTransactions(txn, hash)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// GetBlockWithTransactionsByHeight reads a block and its transactions inside a single badger transaction
+func (b *BadgerDb) GetBlockWithTransactionsByHeight(height uint64) (*BlockWithTransactions, error) {
+	b.commitLock.RLock()
+	defer b.commitLock.RUnlock()
+
+	var entry *BlockWithTransactions
+	if err := b.db.View(func(txn *badger.Txn) error {
+		hash, ok := b.heightCache.get(height)
+		if !ok {
+			var err error
+			if hash, err = readBlockHashByHeight(txn, height); err != nil {
+				return err
+			}
+			b.heightCache.add(height, hash)
+		}
+
+		var err error
+		entry, err = b.readBlockWithTransactions(txn, hash)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// readBlockWithTransactions reads through the caches using the given transaction on a miss
+// The caller must hold commitLock
+func (b *BadgerDb) readBlockWithTransactions(txn *badger.Txn, hash ecommon.Hash) (*BlockWithTransactions, error) {
+	block, err := b.readCachedBlock(txn, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]*types.Transaction, 0, len(block.Transactions))
+	for _, txHash := range block.Transactions {
+		tx, ok := b.transactionCache.get(txHash)
+		if !ok {
+			if tx, err = readTransaction(txn, txHash); err != nil {
+				return nil, err
+			}
+			b.transactionCache.add(txHash, tx)
+		}
+		txs = append(txs, tx)
+	}
+
+	return &BlockWithTransactions{
+		Block:        block,
+		Transactions: txs,
+	}, nil
+}
+
+// readCachedBlock reads a block through the block cache, the caller must hold commitLock
+func (b *BadgerDb) readCachedBlock(txn *badger.Txn, hash ecommon.Hash) (*types.Block, error) {
+	block, ok := b.blockCache.get(hash)
+	if !ok {
+		var err error
+		if block, err = readBlock(txn, hash); err != nil {
+			return nil, err
+		}
+		b.blockCache.add(hash, block)
+	}
+	return block, nil
+}
+
+// ToInfo builds the RPC representation of the block including its transactions
+func (e *BlockWithTransactions) ToInfo() types.BlockInfo {
+	info := e.Block.ToInfo()
+	for _, tx := range e.Transactions {
+		info.Transactions = append(info.Transactions, tx.ToInfo())
+	}
+	return info
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+type CacheStats struct {
+	Capacity int
+	Size     int
+	Hits     uint64
+	Misses   uint64
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// lruCache is a fixed size least recently used cache, a capacity of 0 disables it
+type lruCache[K comparable, V any] struct {
+	capacity int
+	items    map[K]*list.Element
+	order    *list.List
+	lock     sync.Mutex
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func newLruCache[K comparable, V any](capacity int) *lruCache[K, V] {
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &lruCache[K, V]{
+		capacity: capacity,
+		items:    make(map[K]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *lruCache[K, V]) get(key K) (V, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.order.MoveToFront(element)
+		c.hits.Add(1)
+		return element.Value.(*lruEntry[K, V]).value, true
+	}
+	c.misses.Add(1)
+	var empty V
+	return empty, false
+}
+
+func (c *lruCache[K, V]) add(key K, value V) {
+	if c.capacity == 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		element.Value.(*lruEntry[K, V]).value = value
+		c.order.MoveToFront(element)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[K, V]).key)
+	}
+}
+
+func (c *lruCache[K, V]) remove(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.order.Remove(element)
+		delete(c.items, key)
+	}
+}
+
+func (c *lruCache[K, V]) purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.items = make(map[K]*list.Element)
+	c.order.Init()
+}
+
+func (c *lruCache[K, V]) stats() CacheStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return CacheStats{
+		Capacity: c.capacity,
+		Size:     c.order.Len(),
+		Hits:     c.hits.Load(),
+		Misses:   c.misses.Load(),
+	}
+}
@@ -12,8 +12,24 @@ var (
 	transactionPrefix  = []byte{2}
 	blockPrefix        = []byte{3}
 	heightToHashPrefix = []byte{10}
+	accountTxPrefix    = []byte{11}
+
+	reindexCheckpointPrefix = []byte{20}
+	// Marks a database written by a light node, holding block headers only
+	lightPrefix = []byte{21}
+	// The block a validator is signing, until it is stored
+	pendingBlockPrefix = []byte{22}
 )
 
+// derivedPrefixes hold data that can be rebuilt from the stored blocks and transactions
+// Any new secondary index must be added here so reindex knows to rebuild it
+var derivedPrefixes = [][]byte{
+	heightPrefix,
+	accountPrefix,
+	heightToHashPrefix,
+	accountTxPrefix,
+}
+
 func getHeightKey() []byte {
 	return heightPrefix
 }
@@ -33,3 +49,24 @@ func getBlockKey(hash ecommon.Hash) []byte {
 func getHeightToHashKey(height uint64) []byte {
 	return common.JoinBytes(heightToHashPrefix, common.Uint64ToBytes(height))
 }
+
+// getAccountTxKey orders the transactions of an account by height and then by position in the block
+func getAccountTxKey(address ecommon.Address, height uint64, index uint64) []byte {
+	return common.JoinBytes(accountTxPrefix, address.Bytes(), common.Uint64ToBytes(height), common.Uint64ToBytes(index))
+}
+
+func getAccountTxPrefix(address ecommon.Address) []byte {
+	return common.JoinBytes(accountTxPrefix, address.Bytes())
+}
+
+func getReindexCheckpointKey() []byte {
+	return reindexCheckpointPrefix
+}
+
+func getLightKey() []byte {
+	return lightPrefix
+}
+
+func getPendingBlockKey() []byte {
+	return pendingBlockPrefix
+}
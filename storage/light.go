@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"dummy-chain/common/types"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrLightDatabase is returned by the operations that need the transactions and accounts a light database doesn't hold
+	ErrLightDatabase = errors.New("the database only holds block headers")
+	// ErrModeMismatch is returned by Start when the database was written in the other mode
+	ErrModeMismatch = errors.New("the database was written in another mode")
+)
+
+// IsLight tells whether the database was written by a light node
+func (b *BadgerDb) IsLight() (bool, error) {
+	light := false
+	err := b.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(getLightKey())
+		if err == nil {
+			light = true
+			return nil
+		} else if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		}
+		return err
+	})
+	return light, err
+}
+
+// SetHeader stores the next block of a light node without its transactions or any account change
+func (b *BadgerDb) SetHeader(block *types.Block) error {
+	b.commitLock.Lock()
+	defer b.commitLock.Unlock()
+
+	if err := b.db.Update(func(txn *badger.Txn) error {
+		return setHeader(txn, block)
+	}); err != nil {
+		return err
+	}
+
+	b.heightCache.remove(block.Height)
+	b.blockCache.remove(block.Hash)
+	return nil
+}
+
+// startLight stores the genesis header and marks the database as light in a single transaction
+func (b *BadgerDb) startLight(genesis *types.Block) error {
+	b.commitLock.Lock()
+	defer b.commitLock.Unlock()
+
+	return b.db.Update(func(txn *badger.Txn) error {
+		if err := setHeader(txn, genesis); err != nil {
+			return err
+		}
+		return txn.Set(getLightKey(), []byte{1})
+	})
+}
+
+// checkMode refuses to open an existing database in the other mode,
+// a full node would miss the transactions and accounts and a light node would never update the stored accounts
+func (b *BadgerDb) checkMode(light bool) error {
+	stored, err := b.IsLight()
+	if err != nil {
+		return err
+	}
+	switch {
+	case stored && !light:
+		return errors.Wrap(ErrModeMismatch, "it was written by a light node, start with --light")
+	case !stored && light:
+		return errors.Wrap(ErrModeMismatch, "it was written by a full node, start without --light")
+	}
+	return nil
+}
+
+// checkFull returns ErrLightDatabase for a light database
+func (b *BadgerDb) checkFull() error {
+	light, err := b.IsLight()
+	if err != nil {
+		return err
+	} else if light {
+		return ErrLightDatabase
+	}
+	return nil
+}
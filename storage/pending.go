@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/pkg/errors"
+)
+
+// SetPendingBlock keeps the block a validator is about to sign, with its transactions
+// If the validator stops before the block is stored it must sign this exact block again,
+// the slashing protection refuses any other block at its height
+func (b *BadgerDb) SetPendingBlock(block *BlockWithTransactions) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(*block); err != nil {
+		return err
+	}
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(getPendingBlockKey(), buf.Bytes())
+	})
+}
+
+// GetPendingBlock returns the block set by SetPendingBlock that wasn't stored yet, or nil
+func (b *BadgerDb) GetPendingBlock() (*BlockWithTransactions, error) {
+	var pending *BlockWithTransactions
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(getPendingBlockKey())
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		data, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		pending = &BlockWithTransactions{}
+		return gob.NewDecoder(bytes.NewReader(data)).Decode(pending)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// clearPendingBlock drops the pending block once a block at its height is stored, inside the same badger transaction
+func clearPendingBlock(txn *badger.Txn, height uint64) error {
+	item, err := txn.Get(getPendingBlockKey())
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var pending BlockWithTransactions
+	if err = item.Value(func(data []byte) error {
+		return gob.NewDecoder(bytes.NewReader(data)).Decode(&pending)
+	}); err != nil {
+		return err
+	}
+	if pending.Block == nil || pending.Block.Height > height {
+		return nil
+	}
+	return txn.Delete(getPendingBlockKey())
+}
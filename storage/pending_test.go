@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"bytes"
+	"dummy-chain/common"
+	"dummy-chain/common/config"
+	"dummy-chain/common/types"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+	ecommon "github.com/ethereum/go-ethereum/common"
+)
+
+func newTestDb(t *testing.T) *BadgerDb {
+	t.Helper()
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	cacheConfig := config.CacheConfig{Blocks: 16, Transactions: 16, Accounts: 16, Heights: 16}
+	return &BadgerDb{
+		db:               db,
+		blockCache:       newLruCache[ecommon.Hash, *types.Block](cacheConfig.Blocks),
+		transactionCache: newLruCache[ecommon.Hash, *types.Transaction](cacheConfig.Transactions),
+		accountCache:     newLruCache[ecommon.Address, *types.Account](cacheConfig.Accounts),
+		heightCache:      newLruCache[uint64, ecommon.Hash](cacheConfig.Heights),
+	}
+}
+
+func testBlock(height uint64, prevHash ecommon.Hash, timestamp int64) *types.Block {
+	block := &types.Block{
+		ChainId:   common.DummyChainId,
+		Height:    height,
+		Timestamp: timestamp,
+		PrevHash:  prevHash,
+	}
+	block.Hash = block.GetHash()
+	return block
+}
+
+func TestPendingBlock(t *testing.T) {
+	b := newTestDb(t)
+
+	if pending, err := b.GetPendingBlock(); err != nil || pending != nil {
+		t.Fatalf("expected no pending block, got %v %v", pending, err)
+	}
+
+	genesis := testBlock(0, ecommon.Hash{}, 0)
+	if err := b.SetBlock(genesis, nil); err != nil {
+		t.Fatal(err)
+	}
+	next := testBlock(1, genesis.Hash, 100)
+	if err := b.SetPendingBlock(&BlockWithTransactions{Block: next}); err != nil {
+		t.Fatal(err)
+	}
+	pending, err := b.GetPendingBlock()
+	if err != nil || pending == nil || pending.Block.Hash != next.Hash {
+		t.Fatalf("expected the pending block 1, got %v %v", pending, err)
+	}
+
+	// Storing a block below it keeps it
+	if err = b.SetBlock(genesis, nil); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err = b.GetPendingBlock(); err != nil || pending == nil {
+		t.Fatalf("the pending block must survive a lower block, got %v %v", pending, err)
+	}
+
+	if err = b.SetBlock(next, nil); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err = b.GetPendingBlock(); err != nil || pending != nil {
+		t.Fatalf("the pending block must be cleared once stored, got %v %v", pending, err)
+	}
+}
+
+func TestBackupHeight(t *testing.T) {
+	b := newTestDb(t)
+	parent := testBlock(0, ecommon.Hash{}, 0)
+	if err := b.SetBlock(parent, nil); err != nil {
+		t.Fatal(err)
+	}
+	for height := uint64(1); height <= 3; height++ {
+		block := testBlock(height, parent.Hash, int64(height))
+		if err := b.SetBlock(block, nil); err != nil {
+			t.Fatal(err)
+		}
+		parent = block
+	}
+
+	var backup bytes.Buffer
+	if _, err := b.Backup(&backup, 0); err != nil {
+		t.Fatal(err)
+	}
+	height, err := BackupHeight(&backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != 3 {
+		t.Fatalf("expected height 3, got %d", height)
+	}
+}
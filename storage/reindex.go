@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"bytes"
+	"dummy-chain/common/types"
+	"encoding/gob"
+
+	"github.com/dgraph-io/badger/v4"
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Number of blocks applied in a single badger transaction, a checkpoint is saved after each batch
+const reindexBatchSize = 256
+
+type blockLink struct {
+	hash     ecommon.Hash
+	prevHash ecommon.Hash
+}
+
+// ReindexProgress is called after every committed batch with the last applied height and the target height
+type ReindexProgress func(height, target uint64)
+
+// Reindex drops all derived data and rebuilds it from the stored blocks and transactions.
+// The canonical chain is found by following PrevHash links from genesis, so the height index does not need to be intact.
+// A checkpoint is stored after every batch, calling Reindex again after a crash resumes from it.
+func (b *BadgerDb) Reindex(progress ReindexProgress) error {
+	b.commitLock.Lock()
+	defer b.commitLock.Unlock()
+	defer b.purgeCaches()
+
+	if err := b.checkFull(); err != nil {
+		return err
+	}
+	chain, err := b.canonicalChain()
+	if err != nil {
+		return err
+	}
+	if len(chain) == 0 {
+		return errors.New("no genesis block found")
+	}
+	target := uint64(len(chain) - 1)
+
+	next, err := b.getReindexCheckpoint()
+	if err != nil {
+		return err
+	}
+	if next == 0 {
+		// Fresh start or we crashed while dropping, either way nothing was applied yet
+		if err = b.setReindexCheckpoint(0); err != nil {
+			return err
+		}
+		for _, prefix := range derivedPrefixes {
+			if err = b.db.DropPrefix(prefix); err != nil {
+				return err
+			}
+		}
+	} else if next > target+1 {
+		return errors.Errorf("reindex checkpoint %d is past the stored chain height %d", next, target)
+	}
+
+	for next <= target {
+		end := next + reindexBatchSize
+		if end > target+1 {
+			end = target + 1
+		}
+
+		if err = b.db.Update(func(txn *badger.Txn) error {
+			for height := next; height < end; height++ {
+				block, err := readBlock(txn, chain[height])
+				if err != nil {
+					return err
+				}
+				txs := make([]*types.Transaction, 0, len(block.Transactions))
+				for _, txHash := range block.Transactions {
+					tx, err := readTransaction(txn, txHash)
+					if err != nil {
+						return errors.Wrapf(err, "block %d: transaction %s", height, txHash)
+					}
+					txs = append(txs, tx)
+				}
+
+				if err = txn.Set(getHeightToHashKey(height), block.Hash.Bytes()); err != nil {
+					return err
+				}
+				if err = applyTransactions(txn, txs); err != nil {
+					return err
+				}
+			}
+
+			var heightBuf bytes.Buffer
+			if err := gob.NewEncoder(&heightBuf).Encode(end - 1); err != nil {
+				return err
+			} else if err = txn.Set(getHeightKey(), heightBuf.Bytes()); err != nil {
+				return err
+			}
+
+			var checkpointBuf bytes.Buffer
+			if err := gob.NewEncoder(&checkpointBuf).Encode(end); err != nil {
+				return err
+			}
+			return txn.Set(getReindexCheckpointKey(), checkpointBuf.Bytes())
+		}); err != nil {
+			return err
+		}
+
+		next = end
+		if progress != nil {
+			progress(next-1, target)
+		}
+	}
+
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(getReindexCheckpointKey())
+	})
+}
+
+// canonicalChain returns the block hashes ordered by height, following PrevHash links from genesis
+func (b *BadgerDb) canonicalChain() ([]ecommon.Hash, error) {
+	byHeight := make(map[uint64][]blockLink)
+
+	if err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = blockPrefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			data, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			var block types.Block
+			if errDecode := gob.NewDecoder(bytes.NewReader(data)).Decode(&block); errDecode != nil {
+				return errDecode
+			}
+			byHeight[block.Height] = append(byHeight[block.Height], blockLink{
+				hash:     block.Hash,
+				prevHash: block.PrevHash,
+			})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	chain := make([]ecommon.Hash, 0, len(byHeight))
+	prevHash := ecommon.Hash{}
+	for height := uint64(0); ; height++ {
+		found := false
+		for _, link := range byHeight[height] {
+			if link.prevHash == prevHash {
+				chain = append(chain, link.hash)
+				prevHash = link.hash
+				found = true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	return chain, nil
+}
+
+func (b *BadgerDb) getReindexCheckpoint() (uint64, error) {
+	var next uint64
+	if err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(getReindexCheckpointKey())
+		if err != nil {
+			return err
+		}
+		data, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		return gob.NewDecoder(bytes.NewReader(data)).Decode(&next)
+	}); err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return next, nil
+}
+
+func (b *BadgerDb) setReindexCheckpoint(next uint64) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(next); err != nil {
+		return err
+	}
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(getReindexCheckpointKey(), buf.Bytes())
+	})
+}
@@ -3,11 +3,13 @@ package storage
 import (
 	"bytes"
 	"dummy-chain/common"
+	"dummy-chain/common/config"
 	"dummy-chain/common/types"
 	"encoding/gob"
 	"math/big"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/dgraph-io/badger/v4"
 	ecommon "github.com/ethereum/go-ethereum/common"
@@ -19,9 +21,16 @@ import (
 
 type BadgerDb struct {
 	db *badger.DB
+
+	// Read-through caches, commitLock makes a commit and the cache invalidation look atomic to readers
+	blockCache       *lruCache[ecommon.Hash, *types.Block]
+	transactionCache *lruCache[ecommon.Hash, *types.Transaction]
+	accountCache     *lruCache[ecommon.Address, *types.Account]
+	heightCache      *lruCache[uint64, ecommon.Hash]
+	commitLock       sync.RWMutex
 }
 
-func NewBadgerDb(name string) (*BadgerDb, error) {
+func NewBadgerDb(name string, cacheConfig config.CacheConfig) (*BadgerDb, error) {
 	dbDir := filepath.Join(common.DefaultDataDir(), common.DefaultStorageDir, name)
 	if _, err := os.Stat(dbDir); os.IsNotExist(err) {
 		if err = os.MkdirAll(dbDir, 0700); err != nil {
@@ -35,20 +44,25 @@ func NewBadgerDb(name string) (*BadgerDb, error) {
 	}
 
 	return &BadgerDb{
-		db: db,
+		db:               db,
+		blockCache:       newLruCache[ecommon.Hash, *types.Block](cacheConfig.Blocks),
+		transactionCache: newLruCache[ecommon.Hash, *types.Transaction](cacheConfig.Transactions),
+		accountCache:     newLruCache[ecommon.Address, *types.Account](cacheConfig.Accounts),
+		heightCache:      newLruCache[uint64, ecommon.Hash](cacheConfig.Heights),
 	}, nil
 }
 
 // Start This method will simulate the interpretation of the genesis block
 // It should add balances and init accounts to the first 100 indexes of the mnemonic
-func (b *BadgerDb) Start() error {
+// A light database only gets the genesis header
+func (b *BadgerDb) Start(light bool) error {
 	_, err := b.GetBlockHashByHeight(0)
 	if err != nil {
 		if !errors.Is(err, badger.ErrKeyNotFound) {
 			return err
 		}
 	} else {
-		return nil
+		return b.checkMode(light)
 	}
 
 	mnemonic := "margin bounce nominee submit pupil duty bird daughter hotel onion wave write"
@@ -118,6 +132,9 @@ func (b *BadgerDb) Start() error {
 		txs = append(txs, tx)
 	}
 	genesisBlock.Hash = genesisBlock.GetHash()
+	if light {
+		return b.startLight(genesisBlock)
+	}
 	if errSet := b.SetBlock(genesisBlock, txs); errSet != nil {
 		return errSet
 	}
@@ -148,3 +165,38 @@ func (b *BadgerDb) GetHeight() (uint64, error) {
 	}
 	return decodedHeight, nil
 }
+
+// readHeight reads the current height inside an already opened transaction
+func readHeight(txn *badger.Txn) (uint64, error) {
+	item, err := txn.Get(getHeightKey())
+	if err != nil {
+		return 0, err
+	}
+	data, err := item.ValueCopy(nil)
+	if err != nil {
+		return 0, err
+	}
+	var decodedHeight uint64
+	if errDecode := gob.NewDecoder(bytes.NewReader(data)).Decode(&decodedHeight); errDecode != nil {
+		return 0, errDecode
+	}
+	return decodedHeight, nil
+}
+
+// CacheStats returns the size and hit/miss counters of every read cache
+func (b *BadgerDb) CacheStats() map[string]CacheStats {
+	return map[string]CacheStats{
+		"Blocks":       b.blockCache.stats(),
+		"Transactions": b.transactionCache.stats(),
+		"Accounts":     b.accountCache.stats(),
+		"Heights":      b.heightCache.stats(),
+	}
+}
+
+// purgeCaches drops every cached entry, used after bulk changes to the database
+func (b *BadgerDb) purgeCaches() {
+	b.blockCache.purge()
+	b.transactionCache.purge()
+	b.accountCache.purge()
+	b.heightCache.purge()
+}
@@ -15,37 +15,63 @@ func (b *BadgerDb) SetTransaction(transaction *types.Transaction) error {
 		return err
 	}
 
+	b.commitLock.Lock()
+	defer b.commitLock.Unlock()
 	if err := b.db.Update(func(txn *badger.Txn) error {
 		return txn.Set(getTransactionKey(transaction.Hash), buf.Bytes())
 	}); err != nil {
 		return err
 	}
+	b.transactionCache.remove(transaction.Hash)
 	return nil
 }
 
+// GetTransaction returns a transaction that may be shared with the cache, it must not be modified
 func (b *BadgerDb) GetTransaction(hash ecommon.Hash) (*types.Transaction, error) {
-	var decodedTransaction types.Transaction
+	b.commitLock.RLock()
+	defer b.commitLock.RUnlock()
+
+	if tx, ok := b.transactionCache.get(hash); ok {
+		return tx, nil
+	}
+
+	var decodedTransaction *types.Transaction
 	if err := b.db.View(func(txn *badger.Txn) error {
-		item, err := txn.Get(getTransactionKey(hash))
-		if err != nil {
-			return err
-		}
-		data, err := item.ValueCopy(nil)
-		if err != nil {
-			return err
-		}
-		if errDecode := gob.NewDecoder(bytes.NewReader(data)).Decode(&decodedTransaction); errDecode != nil {
-			return errDecode
-		}
-		return nil
+		var err error
+		decodedTransaction, err = readTransaction(txn, hash)
+		return err
 	}); err != nil {
 		return nil, err
 	}
-	return &decodedTransaction, nil
+	b.transactionCache.add(hash, decodedTransaction)
+	return decodedTransaction, nil
 }
 
 func (b *BadgerDb) DeleteTransaction(hash ecommon.Hash) error {
-	return b.db.Update(func(txn *badger.Txn) error {
+	b.commitLock.Lock()
+	defer b.commitLock.Unlock()
+	if err := b.db.Update(func(txn *badger.Txn) error {
 		return txn.Delete(getTransactionKey(hash))
-	})
+	}); err != nil {
+		return err
+	}
+	b.transactionCache.remove(hash)
+	return nil
+}
+
+// readTransaction decodes a transaction inside an already opened transaction
+func readTransaction(txn *badger.Txn, hash ecommon.Hash) (*types.Transaction, error) {
+	item, err := txn.Get(getTransactionKey(hash))
+	if err != nil {
+		return nil, err
+	}
+	data, err := item.ValueCopy(nil)
+	if err != nil {
+		return nil, err
+	}
+	var decodedTransaction types.Transaction
+	if errDecode := gob.NewDecoder(bytes.NewReader(data)).Decode(&decodedTransaction); errDecode != nil {
+		return nil, errDecode
+	}
+	return &decodedTransaction, nil
 }
@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"bytes"
+	"dummy-chain/common"
+	"dummy-chain/common/types"
+	"encoding/gob"
+	"fmt"
+	"math/big"
+
+	"github.com/dgraph-io/badger/v4"
+	ecommon "github.com/ethereum/go-ethereum/common"
+)
+
+type VerifyReport struct {
+	Height       uint64
+	Blocks       uint64
+	Transactions uint64
+	Accounts     uint64
+	Issues       []string
+}
+
+func (r *VerifyReport) addIssue(format string, args ...interface{}) {
+	r.Issues = append(r.Issues, fmt.Sprintf(format, args...))
+}
+
+func (r *VerifyReport) Ok() bool {
+	return len(r.Issues) == 0
+}
+
+// VerifyChain walks the chain from genesis to the current height inside a single read transaction.
+// It checks block hashes, signatures and linkage, then replays every transfer from zero balances
+// and compares the result with the stored accounts.
+// Problems with the data are reported as issues, only storage failures are returned as errors.
+func (b *BadgerDb) VerifyChain() (*VerifyReport, error) {
+	if err := b.checkFull(); err != nil {
+		return nil, err
+	}
+	report := &VerifyReport{
+		Issues: make([]string, 0),
+	}
+
+	if err := b.db.View(func(txn *badger.Txn) error {
+		height, err := readHeight(txn)
+		if err != nil {
+			return err
+		}
+		report.Height = height
+
+		accounts := make(map[ecommon.Address]*types.Account)
+		getAccount := func(address ecommon.Address) *types.Account {
+			if acc, ok := accounts[address]; ok {
+				return acc
+			}
+			acc := &types.Account{
+				Address: address,
+				Nonce:   0,
+				Balance: big.NewInt(0),
+			}
+			accounts[address] = acc
+			return acc
+		}
+
+		var prevHash ecommon.Hash
+		for i := uint64(0); i <= height; i++ {
+			hash, err := readBlockHashByHeight(txn, i)
+			if err != nil {
+				report.addIssue("block %d: missing height index: %s", i, err.Error())
+				continue
+			}
+			block, err := readBlock(txn, hash)
+			if err != nil {
+				report.addIssue("block %d: failed to read block %s: %s", i, hash, err.Error())
+				continue
+			}
+			report.Blocks += 1
+
+			if block.Hash != hash {
+				report.addIssue("block %d: stored hash %s differs from indexed hash %s", i, block.Hash, hash)
+			}
+			if computed := block.GetHash(); computed != block.Hash {
+				report.addIssue("block %d: hash %s does not match computed hash %s", i, block.Hash, computed)
+			}
+			if block.Height != i {
+				report.addIssue("block %d: block reports height %d", i, block.Height)
+			}
+			if block.ChainId != common.DummyChainId {
+				report.addIssue("block %d: unexpected chain id %d", i, block.ChainId)
+			}
+			if block.PrevHash != prevHash {
+				report.addIssue("block %d: previous hash %s does not link to %s", i, block.PrevHash, prevHash)
+			}
+			// The genesis block is not signed
+			if i != 0 {
+				if errSig := block.VerifySignature(); errSig != nil {
+					report.addIssue("block %d: bad validator signature: %s", i, errSig.Error())
+				}
+			}
+			prevHash = block.Hash
+
+			for _, txHash := range block.Transactions {
+				tx, err := readTransaction(txn, txHash)
+				if err != nil {
+					report.addIssue("block %d: failed to read transaction %s: %s", i, txHash, err.Error())
+					continue
+				}
+				report.Transactions += 1
+
+				if computed := tx.GetHash(); computed != tx.Hash || tx.Hash != txHash {
+					report.addIssue("block %d: transaction %s does not match computed hash %s", i, txHash, computed)
+				}
+				if tx.BlockHeight != i {
+					report.addIssue("block %d: transaction %s reports height %d", i, txHash, tx.BlockHeight)
+				}
+				// Genesis allocations come from the empty address and are not signed
+				if i != 0 {
+					if errSig := tx.VerifySignature(); errSig != nil {
+						report.addIssue("block %d: transaction %s has a bad signature: %s", i, txHash, errSig.Error())
+					}
+				}
+
+				// Same balance logic as SetBlock
+				from := getAccount(tx.From)
+				from.Balance.Sub(from.Balance, tx.Value)
+				from.Nonce = tx.Nonce + 1
+				to := getAccount(tx.To)
+				to.Balance.Add(to.Balance, tx.Value)
+			}
+		}
+
+		// Diff the replayed accounts against the stored ones
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = accountPrefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		seen := make(map[ecommon.Address]bool)
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			address := ecommon.BytesToAddress(item.Key()[len(accountPrefix):])
+			data, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			var stored types.Account
+			if errDecode := gob.NewDecoder(bytes.NewReader(data)).Decode(&stored); errDecode != nil {
+				report.addIssue("account %s: failed to decode: %s", address, errDecode.Error())
+				continue
+			}
+			report.Accounts += 1
+			seen[address] = true
+
+			expected, ok := accounts[address]
+			if !ok {
+				report.addIssue("account %s: stored but never used in a transaction", address)
+				continue
+			}
+			if stored.Balance.Cmp(expected.Balance) != 0 {
+				report.addIssue("account %s: stored balance %s, replayed balance %s", address, stored.Balance, expected.Balance)
+			}
+			if stored.Nonce != expected.Nonce {
+				report.addIssue("account %s: stored nonce %d, replayed nonce %d", address, stored.Nonce, expected.Nonce)
+			}
+		}
+
+		for address := range accounts {
+			if !seen[address] {
+				report.addIssue("account %s: missing from storage", address)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}